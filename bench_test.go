@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// Benchmark every coloring algorithm over a grid of graph sizes and degrees
+// Reports the colors used and the share of successful colorings along with time and allocations
+func BenchmarkColoring(b *testing.B) {
+	for _, N := range []int{100, 500} {
+		for _, D := range []float64{2, 4, 8} {
+			g, c := benchmarkGraph(N, D, 1)
+			for _, name := range strategyNames {
+				strategy := coloringStrategies[name]
+				b.Run(fmt.Sprintf("%s/N=%d/D=%g", name, N, D), func(b *testing.B) {
+					b.ReportAllocs()
+					colors, successes := 0, 0
+					for i := 0; i < b.N; i++ {
+						// Every run gets its own time budget, some searches can run for very long
+						ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+						assignment, err := strategy(ctx, g, c, int64(i+1))
+						cancel()
+						colored, used, _ := g.ColoredWith(assignment, c)
+						colors += used
+						if err == nil && colored {
+							successes++
+						}
+					}
+					b.ReportMetric(float64(colors)/float64(b.N), "colors/op")
+					b.ReportMetric(float64(successes)/float64(b.N), "success/op")
+				})
+			}
+		}
+	}
+}
+
+func TestMeasureColoring(t *testing.T) {
+	g, c := benchmarkGraph(20, 3, 1)
+	if ok, _, _, _, _ := measureColoring(coloringStrategies["backtrack"], g, c, 1, 10*time.Second); !ok {
+		t.Error("Backtracking failed on a graph it can color with the greedy bound")
+	}
+
+	// Greedy coloring fails when it needs more than the given colors
+	if ok, _, _, _, _ := measureColoring(coloringStrategies["greedy"], g, 1, 1, time.Second); ok {
+		t.Error("Greedy coloring with one color counted as a success")
+	}
+
+	// A coloring returned without an error still has to be proper
+	improper := func(context.Context, *Graph[string], int, int64) (ColorAssignment[string], error) {
+		a := make(ColorAssignment[string])
+		for n := range g.nodes {
+			a[n] = 0
+		}
+		return a, nil
+	}
+	if ok, _, _, _, _ := measureColoring(improper, g, c, 1, time.Second); ok {
+		t.Error("Improper coloring counted as a success")
+	}
+}
@@ -0,0 +1,268 @@
+// Benchmarks comparing the coloring algorithms
+
+// Every algorithm colors the same random graphs for a grid of sizes N, average degrees D and seeds.
+// The number of colors is the best greedy bound of each graph over all orderings, so the algorithms
+// that try to improve on it can fail, and so can greedy coloring, which only uses the largest-first
+// ordering. A run succeeds if it returns without error and the coloring is proper.
+// The results are averaged over the seeds and saved as CSV or JSON to compare between versions
+
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Coloring algorithm that colors a graph with max c colors
+type coloringStrategy func(ctx context.Context, g *Graph[string], c int, seed int64) (ColorAssignment[string], error)
+
+// Names of the benchmarked algorithms in the order they are run
+var strategyNames = []string{"backtrack", "greedy", "parallel", "tabu", "anneal", "equitable", "balanced", "sat"}
+
+// Coloring algorithms by name
+var coloringStrategies = map[string]coloringStrategy{
+	"backtrack": func(ctx context.Context, g *Graph[string], c int, _ int64) (ColorAssignment[string], error) {
+		return g.ColorIndicesContext(ctx, c)
+	},
+	"greedy": func(ctx context.Context, g *Graph[string], c int, seed int64) (ColorAssignment[string], error) {
+		a, used, err := g.ColorGreedyContext(ctx, LargestFirst, seed)
+		if err == nil && used > c {
+			err = fmt.Errorf("Greedy coloring used %d colors, more than %d", used, c)
+		}
+		return a, err
+	},
+	"parallel": func(ctx context.Context, g *Graph[string], c int, seed int64) (ColorAssignment[string], error) {
+		return g.ColorParallelContext(ctx, c, runtime.NumCPU(), seed)
+	},
+	"tabu": func(ctx context.Context, g *Graph[string], c int, seed int64) (ColorAssignment[string], error) {
+		a, _, err := g.ColorTabuContext(ctx, c, seed, DefaultTabuOptions())
+		return a, err
+	},
+	"anneal": func(ctx context.Context, g *Graph[string], c int, seed int64) (ColorAssignment[string], error) {
+		a, _, err := g.ColorAnnealContext(ctx, c, seed, DefaultAnnealOptions())
+		return a, err
+	},
+	"equitable": func(ctx context.Context, g *Graph[string], c int, seed int64) (ColorAssignment[string], error) {
+		return g.ColorEquitableContext(ctx, c, seed)
+	},
+	"balanced": func(ctx context.Context, g *Graph[string], c int, seed int64) (ColorAssignment[string], error) {
+		return g.ColorBalancedContext(ctx, c, seed)
+	},
+	"sat": func(ctx context.Context, g *Graph[string], c int, _ int64) (ColorAssignment[string], error) {
+		return g.ColorSATContext(ctx, c, ColorConstraints[string]{})
+	},
+}
+
+// Averaged measurements of one algorithm on one graph size and degree
+type BenchmarkResult struct {
+	Algorithm   string  `json:"algorithm"`
+	N           int     `json:"n"`
+	D           float64 `json:"d"`
+	Runs        int     `json:"runs"`
+	SuccessRate float64 `json:"successRate"`
+	Colors      float64 `json:"colors"`     // Mean colors used
+	WallTime    float64 `json:"wallTimeMs"` // Mean milliseconds per run
+	Allocs      float64 `json:"allocs"`     // Mean heap allocations per run
+	AllocBytes  float64 `json:"allocBytes"` // Mean bytes allocated per run
+}
+
+// Options for a benchmark run
+type BenchmarkOptions struct {
+	Algorithms []string
+	Sizes      []int
+	Degrees    []float64
+	Seeds      int
+	Timeout    time.Duration // Time budget for each run
+}
+
+// Generate the benchmark graph for a size, degree and seed
+func benchmarkGraph(N int, D float64, seed int64) (*Graph[string], int) {
+	g, _ := RandomGraph(N, "C", int(float64(N)*D/2.0), seed)
+	_, bound, _ := g.ColorGreedyBest()
+	return g, max(bound, 2)
+}
+
+// Run every algorithm over the grid of sizes, degrees and seeds, calling progress after each result
+func RunBenchmarks(opts BenchmarkOptions, progress func(BenchmarkResult)) ([]BenchmarkResult, error) {
+	for _, name := range opts.Algorithms {
+		if _, ok := coloringStrategies[name]; !ok {
+			return nil, fmt.Errorf("Not a recognized algorithm: %s", name)
+		}
+	}
+
+	results := make([]BenchmarkResult, 0)
+	for _, N := range opts.Sizes {
+		for _, D := range opts.Degrees {
+			graphs := make([]*Graph[string], opts.Seeds)
+			bounds := make([]int, opts.Seeds)
+			for s := range graphs {
+				graphs[s], bounds[s] = benchmarkGraph(N, D, int64(s+1))
+			}
+
+			for _, name := range opts.Algorithms {
+				result := BenchmarkResult{Algorithm: name, N: N, D: D, Runs: opts.Seeds}
+				successes := 0
+				for s, g := range graphs {
+					ok, colors, elapsed, allocs, bytes := measureColoring(coloringStrategies[name], g, bounds[s], int64(s+1), opts.Timeout)
+					if ok {
+						successes++
+					}
+					result.Colors += float64(colors)
+					result.WallTime += float64(elapsed) / float64(time.Millisecond)
+					result.Allocs += float64(allocs)
+					result.AllocBytes += float64(bytes)
+				}
+
+				runs := float64(opts.Seeds)
+				result.SuccessRate = float64(successes) / runs
+				result.Colors /= runs
+				result.WallTime /= runs
+				result.Allocs /= runs
+				result.AllocBytes /= runs
+				results = append(results, result)
+				if progress != nil {
+					progress(result)
+				}
+			}
+		}
+	}
+	return results, nil
+}
+
+// Color a graph once, returns whether the coloring succeeded, colors used, wall time and allocations
+func measureColoring(strategy coloringStrategy, g *Graph[string], c int, seed int64, timeout time.Duration) (bool, int, time.Duration, uint64, uint64) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	assignment, err := strategy(ctx, g, c, seed)
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	colored, colors, _ := g.ColoredWith(assignment, c)
+	return err == nil && colored, colors, elapsed, after.Mallocs - before.Mallocs, after.TotalAlloc - before.TotalAlloc
+}
+
+// Write benchmark results as CSV with a header row
+func WriteBenchmarkCSV(w io.Writer, results []BenchmarkResult) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"algorithm", "n", "d", "runs", "success_rate", "colors", "wall_time_ms", "allocs", "alloc_bytes"})
+	format := func(f float64) string {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	for _, r := range results {
+		writer.Write([]string{r.Algorithm, strconv.Itoa(r.N), format(r.D), strconv.Itoa(r.Runs), format(r.SuccessRate),
+			format(r.Colors), strconv.FormatFloat(r.WallTime, 'f', 3, 64), format(r.Allocs), format(r.AllocBytes)})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// Save benchmark results, as JSON if the path ends in .json and otherwise as CSV
+func SaveBenchmarkResults(results []BenchmarkResult, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(results)
+	}
+	return WriteBenchmarkCSV(file, results)
+}
+
+// Parse a comma separated list of positive integers
+func parseIntList(s string) ([]int, error) {
+	list := make([]int, 0)
+	for _, field := range strings.Split(s, ",") {
+		v, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || v <= 0 {
+			return nil, fmt.Errorf("Invalid positive integer %q", field)
+		}
+		list = append(list, v)
+	}
+	return list, nil
+}
+
+// Parse a comma separated list of positive numbers
+func parseFloatList(s string) ([]float64, error) {
+	list := make([]float64, 0)
+	for _, field := range strings.Split(s, ",") {
+		v, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil || v <= 0 {
+			return nil, fmt.Errorf("Invalid positive number %q", field)
+		}
+		list = append(list, v)
+	}
+	return list, nil
+}
+
+// Program to benchmark the coloring algorithms against each other
+func RunBenchmark() {
+	var Out string // Output file, .csv or .json
+	var err error
+
+	opts := BenchmarkOptions{
+		Algorithms: strategyNames,
+		Sizes:      []int{100, 1000},
+		Degrees:    []float64{2, 4, 8},
+		Seeds:      3,
+		Timeout:    10 * time.Second,
+	}
+
+	// Extract args
+	for i, v := range os.Args {
+		switch v {
+		case "-O", "--O":
+			fmt.Sscanf(ArgValue(i), "%s", &Out)
+		case "-A", "--A":
+			opts.Algorithms = strings.Split(ArgValue(i), ",")
+		case "--sizes":
+			opts.Sizes, err = parseIntList(ArgValue(i))
+		case "--degrees":
+			opts.Degrees, err = parseFloatList(ArgValue(i))
+		case "--seeds":
+			SScanInt(ArgValue(i), &opts.Seeds, "seeds")
+		case "--timeout":
+			SScanDuration(ArgValue(i), &opts.Timeout, "timeout")
+		}
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+
+	fmt.Println("---- Coloring benchmark ----")
+	fmt.Printf("Sizes: %v\nDegrees: %v\nSeeds: %d\nTimeout per run: %v\n", opts.Sizes, opts.Degrees, opts.Seeds, opts.Timeout)
+
+	fmt.Printf("%-10s %7s %6s %8s %7s %12s %12s\n", "algorithm", "N", "D", "success", "colors", "time (ms)", "allocs")
+	results, err := RunBenchmarks(opts, func(r BenchmarkResult) {
+		fmt.Printf("%-10s %7d %6g %7.0f%% %7.2f %12.3f %12.0f\n", r.Algorithm, r.N, r.D, 100*r.SuccessRate, r.Colors, r.WallTime, r.Allocs)
+	})
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	if len(Out) > 0 {
+		if err := SaveBenchmarkResults(results, Out); err != nil {
+			fmt.Println(err)
+		} else {
+			fmt.Printf("Saved to %s\n", Out)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
 	"net/http"
@@ -61,6 +62,15 @@ func PlotFunction(f []float64, minimum int) {
 }
 
 func PlotGraph(g *Graph[string]) {
+	plotGraph(g, "K-Coloring", nil)
+}
+
+// Plot the graph with edges colored by the given colors
+func PlotEdgeColoring(g *Graph[string], linkColors map[Edge[string]]string) {
+	plotGraph(g, "Edge coloring", linkColors)
+}
+
+func plotGraph(g *Graph[string], title string, linkColors map[Edge[string]]string) {
 
 	http.HandleFunc("/", func(w http.ResponseWriter, _ *http.Request) {
 		graph := charts.NewGraph()
@@ -69,7 +79,7 @@ func PlotGraph(g *Graph[string]) {
 			Height: "80vw",
 		}))
 		graph.SetGlobalOptions(charts.WithTitleOpts(opts.Title{
-			Title: "K-Coloring",
+			Title: title,
 		}))
 
 		nodes := make([]opts.GraphNode, len(g.nodes))
@@ -88,11 +98,15 @@ func PlotGraph(g *Graph[string]) {
 
 		i = 0
 		for edge := range g.edges {
+			linkColor := "black"
+			if c, ok := linkColors[edge]; ok {
+				linkColor = c
+			}
 			links[i] = opts.GraphLink{
 				Source: fmt.Sprintf("%d%v", numbering[edge.a], edge.a),
 				Target: fmt.Sprintf("%d%v", numbering[edge.b], edge.b),
 				LineStyle: &opts.LineStyle{
-					Color: "black",
+					Color: linkColor,
 					Width: 2,
 				},
 			}
@@ -114,6 +128,142 @@ func PlotGraph(g *Graph[string]) {
 	}
 }
 
+// Replay a recorded coloring run as an animation with play, pause and step controls
+func PlotColorTrace(g *Graph[string], trace *ColorTrace[string], palette []string) {
+
+	type traceNode struct {
+		Name string `json:"name"`
+	}
+	type traceLink struct {
+		Source int `json:"source"`
+		Target int `json:"target"`
+	}
+	type traceStep struct {
+		Kind  string `json:"kind"`
+		Node  int    `json:"node"`
+		Color int    `json:"color"`
+		Value int    `json:"value"`
+	}
+	type traceData struct {
+		Nodes   []traceNode `json:"nodes"`
+		Links   []traceLink `json:"links"`
+		Steps   []traceStep `json:"steps"`
+		Palette []string    `json:"palette"`
+	}
+
+	nodes, index, _ := g.adjacencyLists()
+	data := traceData{Palette: palette}
+	// Node values hold the final colors, so only number the nodes
+	for i := range nodes {
+		data.Nodes = append(data.Nodes, traceNode{fmt.Sprint(i + 1)})
+	}
+	for e := range g.edges {
+		data.Links = append(data.Links, traceLink{index[e.a], index[e.b]})
+	}
+	for _, step := range trace.Steps {
+		node := -1
+		if step.Node != nil {
+			node = index[step.Node]
+		}
+		data.Steps = append(data.Steps, traceStep{step.Kind, node, step.Color, step.Value})
+	}
+
+	http.HandleFunc("/", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `
+		<!doctype html>
+		<html>
+			<head>
+				<title>Coloring playback</title>
+				<script src="https://go-echarts.github.io/go-echarts-assets/assets/`+opts.EchartsJS+`"></script>
+			</head>
+			<body>
+				<h1 style="text-align: center;">K-Coloring playback</h1>
+				<div style="display: flex; justify-content: center; gap: 1em; align-items: center;">
+					<button id="play">Play</button>
+					<button id="step">Step</button>
+					<input id="slider" type="range" min="0" value="0" style="width: 40vw;" />
+					<span id="status"></span>
+				</div>
+				<div id="chart" style="width: 80vw; height: 80vh; margin: auto;"></div>
+				<script>
+					fetch("/trace.json").then(r => r.json()).then(data => {
+						const chart = echarts.init(document.getElementById("chart"));
+						const slider = document.getElementById("slider");
+						const status = document.getElementById("status");
+						const play = document.getElementById("play");
+						slider.max = data.steps.length;
+
+						let position = 0, colors = [], timer = null, message = "";
+
+						// Apply steps until the position, restarting when going backwards
+						const seek = target => {
+							if (target < position) {
+								position = 0;
+								colors = data.nodes.map(() => -1);
+							}
+							for (; position < target; position++) {
+								const s = data.steps[position];
+								if (s.kind === "assign") {
+									colors[s.node] = s.color;
+									message = "Assign " + data.nodes[s.node].name + " color " + s.color;
+								} else if (s.kind === "tree") {
+									message = "Tree built, conflict edges: " + s.value;
+								} else if (s.kind === "pass") {
+									message = "Pass, incorrect: " + s.value;
+								} else {
+									message = "Backtrack to depth " + s.value;
+								}
+							}
+							slider.value = position;
+							status.textContent = position + " / " + data.steps.length + " " + message;
+							chart.setOption({ series: [{ data: data.nodes.map((n, i) => ({
+								name: n.name,
+								itemStyle: { color: colors[i] < 0 ? "lightgray" : data.palette[colors[i]] },
+							})) }] });
+						};
+
+						chart.setOption({
+							series: [{
+								type: "graph", layout: "force", roam: true, draggable: true,
+								force: { repulsion: 800 },
+								data: data.nodes.map(n => ({ name: n.name })),
+								links: data.links.map(l => ({ source: data.nodes[l.source].name, target: data.nodes[l.target].name })),
+								lineStyle: { color: "black", width: 2 },
+							}],
+						});
+						colors = data.nodes.map(() => -1);
+						seek(0);
+
+						play.onclick = () => {
+							if (timer) {
+								clearInterval(timer);
+								timer = null;
+								play.textContent = "Play";
+							} else {
+								play.textContent = "Pause";
+								timer = setInterval(() => position < data.steps.length ? seek(position + 1) : play.onclick(), 100);
+							}
+						};
+						document.getElementById("step").onclick = () => seek(Math.min(position + 1, data.steps.length));
+						slider.oninput = () => seek(Number(slider.value));
+					});
+				</script>
+			</body>
+		</html>
+		`)
+	})
+
+	http.HandleFunc("/trace.json", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(data)
+	})
+
+	fmt.Println("Starting server on http://localhost:8080")
+	if err := http.ListenAndServe(":8080", nil); err != nil {
+		fmt.Println(err)
+	}
+}
+
 func PlotImage(N int, img []bool, record image.Rectangle) {
 
 	newImg := image.NewRGBA(image.Rectangle{
@@ -186,3 +336,34 @@ func PlotImage(N int, img []bool, record image.Rectangle) {
 		fmt.Println(err)
 	}
 }
+
+// Serve an SVG image with a title
+func PlotSVG(title string, svg string) {
+
+	http.HandleFunc("/", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintf(w, `
+		<!doctype html>
+		<html>
+			<head>
+				<title>%s</title>
+			</head>
+			<body>
+				<div style="width: 100%%; height: 100%%; display: flex; flex-direction: column; justify-content: center; align-items: center;">
+					<h1 style="text-align: center;">%s</h1>
+					<img src="/img.svg" style="width: 60vw; height: auto;" />
+				</div>
+			</body>
+		</html>
+		`, xmlEscape(title), xmlEscape(title))
+	})
+
+	http.HandleFunc("/img.svg", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "image/svg+xml")
+		fmt.Fprint(w, svg)
+	})
+
+	fmt.Println("Starting server on http://localhost:8080")
+	if err := http.ListenAndServe(":8080", nil); err != nil {
+		fmt.Println(err)
+	}
+}
@@ -0,0 +1,178 @@
+// Chromatic polynomial of small graphs
+
+// The chromatic polynomial P(x) counts the proper colorings of a graph with x colors.
+// It follows from deletion-contraction: P(G) = P(G - e) - P(G / e) for any edge e,
+// since colorings of G - e either give the endpoints of e different colors (colorings of G)
+// or the same color (colorings of G / e). Graphs are memoized by their canonical form, so
+// isomorphic subproblems are only computed once. The work grows exponentially with the edges
+
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Polynomial with integer coefficients, index i holds the coefficient of x^i
+type Polynomial []*big.Int
+
+// Polynomial x^n
+func monomial(n int) Polynomial {
+	p := make(Polynomial, n+1)
+	for i := range p {
+		p[i] = new(big.Int)
+	}
+	p[n].SetInt64(1)
+	return p
+}
+
+// Subtract polynomial q from p
+func (p Polynomial) Sub(q Polynomial) Polynomial {
+	r := make(Polynomial, max(len(p), len(q)))
+	for i := range r {
+		r[i] = new(big.Int)
+		if i < len(p) {
+			r[i].Add(r[i], p[i])
+		}
+		if i < len(q) {
+			r[i].Sub(r[i], q[i])
+		}
+	}
+	return r
+}
+
+// Multiply polynomial p by (x - a)
+func (p Polynomial) MulLinear(a int64) Polynomial {
+	r := make(Polynomial, len(p)+1)
+	for i := range r {
+		r[i] = new(big.Int)
+	}
+	for i, c := range p {
+		r[i+1].Add(r[i+1], c)
+		r[i].Sub(r[i], new(big.Int).Mul(c, big.NewInt(a)))
+	}
+	return r
+}
+
+// Evaluate the polynomial at x
+func (p Polynomial) Eval(x int64) *big.Int {
+	result := new(big.Int)
+	for i := len(p) - 1; i >= 0; i-- {
+		result.Mul(result, big.NewInt(x))
+		result.Add(result, p[i])
+	}
+	return result
+}
+
+// Format a polynomial to string, highest power first
+func (p Polynomial) String() string {
+	terms := make([]string, 0)
+	for i := len(p) - 1; i >= 0; i-- {
+		c := p[i]
+		if c.Sign() == 0 {
+			continue
+		}
+		sign := "+"
+		if c.Sign() < 0 {
+			sign = "-"
+		}
+		abs := new(big.Int).Abs(c)
+
+		var term string
+		switch {
+		case i == 0:
+			term = abs.String()
+		case abs.Cmp(big.NewInt(1)) == 0:
+			term = "x"
+		default:
+			term = abs.String() + "x"
+		}
+		if i > 1 {
+			term += fmt.Sprintf("^%d", i)
+		}
+
+		if len(terms) == 0 {
+			if sign == "-" {
+				term = "-" + term
+			}
+			terms = append(terms, term)
+		} else {
+			terms = append(terms, sign, term)
+		}
+	}
+	if len(terms) == 0 {
+		return "0"
+	}
+	return strings.Join(terms, " ")
+}
+
+// Compute the chromatic polynomial of the graph, only feasible for small graphs
+func (g *Graph[T]) ChromaticPolynomial() Polynomial {
+
+	// Only the structure matters, so drop the node values to share more subproblems
+	structure := EmptyGraph[bool]()
+	mapping := make(map[*Node[T]]*Node[bool], len(g.nodes))
+	for _, n := range g.Nodes() {
+		mapping[n] = structure.AddNodes(1, false)[0]
+	}
+	for e := range g.edges {
+		structure.Connect(Edge[bool]{mapping[e.a], mapping[e.b]})
+	}
+
+	return chromatic(structure, make(map[string]Polynomial))
+}
+
+// Deletion-contraction with memoization on canonical forms
+func chromatic(g *Graph[bool], memo map[string]Polynomial) Polynomial {
+	N := len(g.nodes)
+
+	// Graphs without edges can color each node freely
+	if len(g.edges) == 0 {
+		return monomial(N)
+	}
+
+	// Complete graphs need a different color for every node: x(x-1)...(x-N+1)
+	if len(g.edges) == N*(N-1)/2 {
+		p := monomial(0)
+		for i := 0; i < N; i++ {
+			p = p.MulLinear(int64(i))
+		}
+		return p
+	}
+
+	key := g.CanonicalForm()
+	if p, ok := memo[key]; ok {
+		return p
+	}
+
+	var p Polynomial
+	nodes := g.Nodes()
+
+	// Isolated nodes take any color, leaves anything but their neighbour's color
+	for _, n := range nodes {
+		if degree := len(n.neighbours); degree < 2 {
+			rest, mapping := g.Clone()
+			rest.RemoveNode(mapping[n])
+			p = chromatic(rest, memo).MulLinear(int64(degree))
+			break
+		}
+	}
+
+	// Otherwise every node has an edge, delete and contract one
+	if p == nil {
+		e := nodes[0].sortedEdges()[0]
+		deleted, mapping := g.Clone()
+		deleted.Disconnect(Edge[bool]{mapping[e.a], mapping[e.b]})
+		contracted, _ := g.Contract(e)
+		p = chromatic(deleted, memo).Sub(chromatic(contracted, memo))
+	}
+
+	memo[key] = p
+	return p
+}
+
+// Count the proper colorings of the graph with k colors
+func (g *Graph[T]) CountColorings(k int) *big.Int {
+	return g.ChromaticPolynomial().Eval(int64(k))
+}
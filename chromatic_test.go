@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/big"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// Random graph with N nodes where each pair is connected with probability p
+func randomDenseGraph(r *rand.Rand, N int, p float64) *Graph[int] {
+	g := NewGraph(N, 0)
+	nodes := g.Nodes()
+	for i, a := range nodes {
+		for _, b := range nodes[i+1:] {
+			if r.Float64() < p {
+				g.Connect(Edge[int]{a, b})
+			}
+		}
+	}
+	return g
+}
+
+// Count the proper k-colorings by trying every color on every node in id order,
+// stopping once limit colorings are found
+func bruteForceColorings[T comparable](g *Graph[T], k int, limit int64) int64 {
+	nodes, _, adjacency := g.adjacencyLists()
+	colors := make([]int, len(nodes))
+	count := int64(0)
+	var assign func(i int)
+	assign = func(i int) {
+		if count >= limit {
+			return
+		}
+		if i == len(nodes) {
+			count++
+			return
+		}
+		for c := 0; c < k; c++ {
+			proper := true
+			for _, j := range adjacency[i] {
+				if j < i && colors[j] == c {
+					proper = false
+					break
+				}
+			}
+			if proper {
+				colors[i] = c
+				assign(i + 1)
+			}
+		}
+	}
+	assign(0)
+	return count
+}
+
+// Path with n nodes
+func pathGraph(n int) *Graph[int] {
+	g := NewGraph(n, 0)
+	nodes := g.Nodes()
+	for i := 0; i+1 < n; i++ {
+		g.Connect(Edge[int]{nodes[i], nodes[i+1]})
+	}
+	return g
+}
+
+// Cycle with n nodes
+func cycleGraph(n int) *Graph[int] {
+	g := pathGraph(n)
+	nodes := g.Nodes()
+	g.Connect(Edge[int]{nodes[n-1], nodes[0]})
+	return g
+}
+
+// Integer power of x
+func power(x int64, n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(x), big.NewInt(int64(n)), nil)
+}
+
+func TestCountColoringsBruteForce(t *testing.T) {
+	r := rand.New(rand.NewSource(12))
+	for round := 0; round < 300; round++ {
+		// Disconnected graphs are counted too, as the product of their components
+		g := randomDenseGraph(r, 1+r.Intn(7), r.Float64())
+		for k := 0; k <= 4; k++ {
+			expected := bruteForceColorings(g, k, math.MaxInt64)
+			if got := g.CountColorings(k); got.Cmp(big.NewInt(expected)) != 0 {
+				t.Fatalf("Round %d: %d colorings with %d colors, enumeration found %d\n%s", round, got, k, expected, g)
+			}
+		}
+	}
+}
+
+func TestChromaticPolynomialClosedForms(t *testing.T) {
+	for n := 1; n <= 9; n++ {
+		for k := int64(0); k <= 6; k++ {
+			// A path colors its first node freely and every other node differently from its predecessor
+			path := new(big.Int).Mul(big.NewInt(k), power(k-1, n-1))
+			if got := pathGraph(n).CountColorings(int(k)); got.Cmp(path) != 0 {
+				t.Errorf("Path with %d nodes has %d colorings with %d colors, expected %d", n, got, k, path)
+			}
+
+			// Complete graph k(k-1)...(k-n+1)
+			complete := big.NewInt(1)
+			for i := 0; i < n; i++ {
+				complete.Mul(complete, big.NewInt(k-int64(i)))
+			}
+			if got := CompleteGraph(n, 0).CountColorings(int(k)); got.Cmp(complete) != 0 {
+				t.Errorf("Complete graph with %d nodes has %d colorings with %d colors, expected %d", n, got, k, complete)
+			}
+
+			// Cycle (k-1)^n + (-1)^n (k-1)
+			if n < 3 {
+				continue
+			}
+			cycle := power(k-1, n)
+			if n%2 == 0 {
+				cycle.Add(cycle, big.NewInt(k-1))
+			} else {
+				cycle.Sub(cycle, big.NewInt(k-1))
+			}
+			if got := cycleGraph(n).CountColorings(int(k)); got.Cmp(cycle) != 0 {
+				t.Errorf("Cycle with %d nodes has %d colorings with %d colors, expected %d", n, got, k, cycle)
+			}
+		}
+	}
+}
+
+func TestColorFailsExactlyWithoutColorings(t *testing.T) {
+	r := rand.New(rand.NewSource(8))
+	for round := 0; round < 300; round++ {
+		N := 2 + r.Intn(7)
+		g := randomDenseGraph(r, N, 0.3+0.6*r.Float64())
+		if connected, _ := g.Connected(); !connected {
+			continue
+		}
+
+		for k := 2; k <= N; k++ {
+			// Enumeration only has to find a single coloring
+			colorable := bruteForceColorings(g, k, 1) > 0
+			if colorable != (g.CountColorings(k).Sign() > 0) {
+				t.Fatalf("Round %d: enumeration and count disagree on coloring with %d colors", round, k)
+			}
+
+			// A deadline only guards the test, the search has to finish well before it
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			assignment, err := g.ColorIndicesContext(ctx, k)
+			cancel()
+			if errors.Is(err, ErrTimeout) {
+				t.Fatalf("Round %d: coloring with %d colors did not finish", round, k)
+			}
+			if colorable != (err == nil) {
+				t.Fatalf("Round %d: colorable %v with %d colors but coloring returned %v", round, colorable, k, err)
+			}
+			if err == nil {
+				if ok, _, _ := g.ColoredWith(assignment, k); !ok {
+					t.Fatalf("Round %d: improper coloring with %d colors", round, k)
+				}
+			}
+		}
+	}
+}
+
+func TestColorIndicesTerminates(t *testing.T) {
+	r := rand.New(rand.NewSource(9))
+	for round := 0; round < 100; round++ {
+		g := randomDenseGraph(r, 6+r.Intn(6), 0.5)
+		if connected, _ := g.Connected(); !connected {
+			continue
+		}
+		done := make(chan struct{})
+		go func() {
+			// Without a deadline the search must end by itself
+			g.ColorIndices(3)
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(10 * time.Second):
+			t.Fatalf("Round %d: coloring without a deadline did not finish", round)
+		}
+	}
+}
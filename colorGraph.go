@@ -1,32 +1,92 @@
 // Implementation of graph coloring algorithm
 
-// Build a two-colored spanning tree, then conflict resolve until the graph is colored
-// or error if all options are exhausted
+// Build a two-colored spanning tree, then conflict resolve until the graph is colored.
+// If the resolution runs out of options, an exhaustive search either finds a coloring
+// or proves that none exists
 
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
+	"runtime"
 	"time"
 )
 
-// Color a graph G with max c colors
+// Assignment of color indexes to nodes, kept separate from the node values
+type ColorAssignment[T comparable] map[*Node[T]]int
+
+// Returned when an algorithm is cancelled or runs out of time, along with the best result so far
+var ErrTimeout = errors.New("Stopped before finishing")
+
+// Wrap the reason the context ended in ErrTimeout
+func timeoutError(ctx context.Context) error {
+	return fmt.Errorf("%w: %w", ErrTimeout, context.Cause(ctx))
+}
+
+// Color a graph G with max c colors, writing the colors into the node values
 func (g *Graph[T]) Color(c int, colors []T) error {
 
+	if len(colors) < c {
+		return errors.New("Not enough colors to color the graph")
+	}
+
+	assignment, err := g.ColorIndices(c)
+	if assignment != nil {
+		// Also write partial colorings, so conflicts can be inspected
+		assignment.Apply(colors)
+	}
+	return err
+}
+
+// Color a graph G with max c colors, returning the color index of each node
+// If the coloring fails, the last attempted assignment is returned with the error
+func (g *Graph[T]) ColorIndices(c int) (ColorAssignment[T], error) {
+	return g.ColorIndicesContext(context.Background(), c)
+}
+
+// Color a graph G with max c colors, stopping when the context is done
+// On timeout, the coloring with the fewest unresolved nodes is returned with ErrTimeout
+func (g *Graph[T]) ColorIndicesContext(ctx context.Context, c int) (ColorAssignment[T], error) {
+	return g.ColorObserved(ctx, c, nil)
+}
+
+// Color a graph G with max c colors, reporting the progress of the algorithm to the observer
+func (g *Graph[T]) ColorObserved(ctx context.Context, c int, observer ColorObserver) (ColorAssignment[T], error) {
+	return g.colorTree(ctx, c, ColorConstraints[T]{}, observer)
+}
+
+// Build the two-colored tree and resolve conflicts while honoring the constraints
+func (g *Graph[T]) colorTree(ctx context.Context, c int, constraints ColorConstraints[T], observer ColorObserver) (ColorAssignment[T], error) {
+
+	if observer == nil {
+		observer = nopObserver{}
+	}
+
 	if g.root == nil {
-		return errors.New("Graph is empty")
+		return nil, errors.New("Graph is empty")
 	}
 
 	if connected, _ := g.Connected(); !connected {
-		return errors.New("Graph is not connected")
+		return nil, errors.New("Graph is not connected")
 	}
 
-	if len(colors) < c {
-		return errors.New("Not enough colors to color the graph")
+	if c < 1 || (c < 2 && len(g.edges) > 0) {
+		return nil, errors.New("Not enough colors to color the graph")
 	}
 
+	color := make(ColorAssignment[T], len(g.nodes))
+
+	// Record every color change for observers that follow assignments
+	assignObserver, tracing := observer.(AssignObserver[T])
+	assign := func(node *Node[T], k int) {
+		color[node] = k
+		if tracing {
+			assignObserver.Assign(node, k)
+		}
+	}
 	ordering := make(map[*Node[T]]int) // Store the ordering of nodes in the constructed tree
 	conflicts := make(EdgeSet[T])      // Store the conflict edges that are not part of the tree
 
@@ -35,20 +95,11 @@ func (g *Graph[T]) Color(c int, colors []T) error {
 
 		// Assign the node an ordering and color it based on the numbers parity
 		ordering[node] = i
-		if i%2 == 0 {
-			node.value = colors[0]
-		} else {
-			node.value = colors[1]
-		}
+		assign(node, i%2)
 
 		// Iterate edges
 		for e := range node.neighbours {
-			var n *Node[T]
-			if node != e.a {
-				n = e.a
-			} else {
-				n = e.b
-			}
+			n := e.Other(node)
 
 			// This is the parent node so skip it
 			if o, ok := ordering[n]; ok && o == i-1 {
@@ -65,12 +116,29 @@ func (g *Graph[T]) Color(c int, colors []T) error {
 	// Tree-building step
 	g.root.Walk(make(NodeSet[T]), 0, twoColor)
 
+	// Constrained nodes take a color they are allowed, which can conflict with tree edges too
+	for node := range g.nodes {
+		if !constraints.constrained(node) {
+			continue
+		}
+		allowed := constraints.allowed(node, c)
+		if _, ok := allowed[color[node]]; !ok {
+			for _, p := range constraints.ordered(node, c) {
+				assign(node, p)
+				break
+			}
+		}
+		for e := range node.neighbours {
+			conflicts[e] = true
+		}
+	}
+
 	// Type to keep a backlog of color changes
 	type ColorStep struct {
 		node     *Node[T]
-		current  T
-		original T
-		possible map[T]struct{}
+		current  int
+		original int
+		possible map[int]struct{}
 	}
 
 	// Track possible color permutations
@@ -79,25 +147,21 @@ func (g *Graph[T]) Color(c int, colors []T) error {
 	// Assign a non-conflicting color to a node
 	chooseColor := func(node *Node[T]) int {
 
-		// All choices
-		possible := make(map[T]struct{})
-		for _, color := range colors {
-			possible[color] = struct{}{}
+		// Locked nodes never change, their neighbours have to
+		if _, ok := constraints.Locked[node]; ok {
+			return 0
 		}
 
+		// All choices
+		possible := constraints.allowed(node, c)
+
 		// Remove choices conflicting with neighbours
 		for e := range node.neighbours {
-			var n *Node[T]
-			if node != e.a {
-				n = e.a
-			} else {
-				n = e.b
-			}
-			delete(possible, n.value)
+			delete(possible, color[e.Other(node)])
 		}
 
 		// Already satisfies coloring
-		if _, ok := possible[node.value]; ok {
+		if _, ok := possible[color[node]]; ok {
 			return 0
 		}
 
@@ -109,8 +173,8 @@ func (g *Graph[T]) Color(c int, colors []T) error {
 		// Choose first possible option
 		for p := range possible {
 			// Track the choice
-			backlog = append(backlog, ColorStep{node, p, node.value, possible})
-			node.value = p
+			backlog = append(backlog, ColorStep{node, p, color[node], possible})
+			assign(node, p)
 			return 0
 		}
 		return 1
@@ -124,16 +188,17 @@ func (g *Graph[T]) Color(c int, colors []T) error {
 				delete(s.possible, s.current) // This choice lead to a dead end, remove it
 				for p := range s.possible {
 					s.current = p // Next option
-					s.node.value = p
+					assign(s.node, p)
 					break
 				}
 				// Clear backlog after i
 				for j := i + 1; j < len(backlog); j++ {
-					backlog[j].node.value = backlog[j].original
+					assign(backlog[j].node, backlog[j].original)
 				}
-				// Restart backlog keeping from i
-				backlog = backlog[:i+2]
+				// Restart backlog keeping the changed choice at i
+				backlog = backlog[:i+1]
 
+				observer.Backtrack(i, len(backlog))
 				return false
 			}
 		}
@@ -143,8 +208,42 @@ func (g *Graph[T]) Color(c int, colors []T) error {
 	// Conflict resolution step
 	incorrect := len(conflicts)
 	backlen := len(backlog)
+	observer.TreeBuilt(len(conflicts))
+
+	// Keep the best coloring in case we run out of time
+	best := make(ColorAssignment[T], len(color))
+	bestIncorrect := incorrect
+	for n, k := range color {
+		best[n] = k
+	}
 
-	for incorrect > 0 {
+	// The resolution step is a heuristic, when it runs out of options an exhaustive search decides
+	exhaustive := func() (ColorAssignment[T], error) {
+		exact, err := g.colorExact(ctx, c, constraints)
+		if exact == nil {
+			if errors.Is(err, ErrTimeout) {
+				return best, err
+			}
+			return color, err
+		}
+		for n, k := range exact {
+			if color[n] != k {
+				assign(n, k)
+			}
+		}
+		return color, nil
+	}
+
+	// Color changes can keep cycling without getting stuck, so the resolution gets a limited number of passes
+	maxPasses := 4 * len(g.nodes) * c
+
+	for pass := 1; incorrect > 0; pass++ {
+		if ctx.Err() != nil {
+			return best, timeoutError(ctx)
+		}
+		if pass > maxPasses {
+			return exhaustive()
+		}
 		incorrect = 0
 
 		// Pass over conflicts, assigning colors
@@ -152,24 +251,149 @@ func (g *Graph[T]) Color(c int, colors []T) error {
 			incorrect += chooseColor(e.a)
 			incorrect += chooseColor(e.b)
 		}
+		observer.Pass(pass, incorrect)
 
 		// All resolved
 		if incorrect < 1 {
 			break
 		}
 
+		if incorrect < bestIncorrect {
+			bestIncorrect = incorrect
+			for n, k := range color {
+				best[n] = k
+			}
+		}
+
 		changes := len(backlog) - backlen
 		if changes < 1 {
 			// The pass made no color changes, the configuration is stuck so backtrack
 			if backtrack() {
 				// All options exhausted
-				return errors.New("Exhausted all configurations, graph might not be colorable")
+				return exhaustive()
 			}
 		} else {
 			backlen = len(backlog)
 		}
 	}
 
+	return color, nil
+}
+
+// Search every coloring of the graph with max c colors that honors the constraints
+// Nodes are colored in DSatur order, the node with the fewest colors left first, and the search
+// backtracks when a node has no colors left. Returns ErrNotColorable when no coloring exists
+func (g *Graph[T]) colorExact(ctx context.Context, c int, constraints ColorConstraints[T]) (ColorAssignment[T], error) {
+
+	nodes, _, adjacency := g.adjacencyLists()
+	N := len(nodes)
+
+	// blocked[i*c+k] counts the colored neighbours of node i with color k
+	allowed := make([]bool, N*c)
+	blocked := make([]int, N*c)
+	color := make([]int, N)
+	for i, n := range nodes {
+		color[i] = -1
+		for k := range constraints.allowed(n, c) {
+			allowed[i*c+k] = true
+		}
+	}
+
+	// Without constraints colors are interchangeable, so a node never needs more than one unused color
+	symmetric := len(constraints.Locked) == 0 && len(constraints.Allowed) == 0
+	used := 0
+	steps := 0
+
+	// Count the colors node i can still take
+	options := func(i int) int {
+		count := 0
+		for k := 0; k < c; k++ {
+			if allowed[i*c+k] && blocked[i*c+k] == 0 {
+				count++
+			}
+		}
+		return count
+	}
+
+	var search func(colored int) (bool, error)
+	search = func(colored int) (bool, error) {
+		if colored == N {
+			return true, nil
+		}
+		steps++
+		if steps%contextCheckInterval == 0 && ctx.Err() != nil {
+			return false, timeoutError(ctx)
+		}
+
+		// Pick the uncolored node with the fewest options, breaking ties by degree
+		next, fewest := -1, c+1
+		for i := range nodes {
+			if color[i] >= 0 {
+				continue
+			}
+			o := options(i)
+			if o < fewest || (o == fewest && len(adjacency[i]) > len(adjacency[next])) {
+				next, fewest = i, o
+			}
+		}
+		if fewest == 0 {
+			return false, nil
+		}
+
+		limit := c
+		if symmetric {
+			limit = min(c, used+1)
+		}
+		for k := 0; k < limit; k++ {
+			if !allowed[next*c+k] || blocked[next*c+k] > 0 {
+				continue
+			}
+			color[next] = k
+			for _, j := range adjacency[next] {
+				blocked[j*c+k]++
+			}
+			previous := used
+			used = max(used, k+1)
+
+			ok, err := search(colored + 1)
+			if ok || err != nil {
+				return ok, err
+			}
+
+			used = previous
+			for _, j := range adjacency[next] {
+				blocked[j*c+k]--
+			}
+			color[next] = -1
+		}
+		return false, nil
+	}
+
+	ok, err := search(0)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w with %d colors", ErrNotColorable, c)
+	}
+
+	assignment := make(ColorAssignment[T], N)
+	for i, n := range nodes {
+		assignment[n] = color[i]
+	}
+	return assignment, nil
+}
+
+// Write the assigned colors into the node values
+func (a ColorAssignment[T]) Apply(colors []T) error {
+	for _, i := range a {
+		if i < 0 || i >= len(colors) {
+			return fmt.Errorf("Color index %d is out of range", i)
+		}
+	}
+	for n, i := range a {
+		n.value = colors[i]
+	}
 	return nil
 }
 
@@ -181,6 +405,18 @@ func RunGraphColor() {
 	var noPrint bool   // Use this for large N to prevent filling the terminal
 	var noVisuals bool // Do not visualize the graph
 	var noSave bool    // Disable save prompt
+	var edges bool     // Color edges instead of nodes
+	var animate bool   // Record the coloring run for playback
+
+	var algorithm = "backtrack"    // Coloring algorithm to use
+	var workers = runtime.NumCPU() // Worker goroutines for parallel coloring
+	var tabuOpts = DefaultTabuOptions()
+	var annealOpts = DefaultAnnealOptions()
+	var iterations int
+	var K int                          // Number of colors, chosen by greedy coloring if not given
+	var orderingName = "largest-first" // Node ordering for greedy coloring
+	var timeout time.Duration          // Time budget for generating and coloring the graph
+	var dimacs string                  // File to export the SAT encoding to
 
 	var seed = time.Now().UnixNano()
 	var intSeed int
@@ -189,19 +425,39 @@ func RunGraphColor() {
 	for i, v := range os.Args {
 		switch v {
 		case "-N", "--N":
-			SScanInt(os.Args[i+1], &N, "N")
+			SScanInt(ArgValue(i), &N, "N")
 		case "-D", "--D":
-			SScanFloat(os.Args[i+1], &D, "D")
+			SScanFloat(ArgValue(i), &D, "D")
 		case "-S", "--S":
-			SScanInt(os.Args[i+1], &intSeed, "seed")
+			SScanInt(ArgValue(i), &intSeed, "seed")
 		case "-O", "--O":
-			fmt.Sscanf(os.Args[i+1], "%s", &Out)
+			fmt.Sscanf(ArgValue(i), "%s", &Out)
+		case "-A", "--A":
+			fmt.Sscanf(ArgValue(i), "%s", &algorithm)
+		case "-W", "--W":
+			SScanInt(ArgValue(i), &workers, "W")
+		case "--tenure":
+			SScanInt(ArgValue(i), &tabuOpts.Tenure, "tenure")
+		case "--iterations":
+			SScanInt(ArgValue(i), &iterations, "iterations")
+		case "-K", "--K":
+			SScanInt(ArgValue(i), &K, "K")
+		case "--ordering":
+			fmt.Sscanf(ArgValue(i), "%s", &orderingName)
+		case "--timeout":
+			SScanDuration(ArgValue(i), &timeout, "timeout")
+		case "--dimacs":
+			fmt.Sscanf(ArgValue(i), "%s", &dimacs)
 		case "--noprint":
 			noPrint = true
 		case "--novisuals":
 			noVisuals = true
 		case "--nosave":
 			noSave = true
+		case "--edges":
+			edges = true
+		case "--animate":
+			animate = true
 		}
 	}
 
@@ -221,6 +477,11 @@ func RunGraphColor() {
 		seed = int64(intSeed)
 	}
 
+	if iterations > 0 {
+		tabuOpts.MaxIterations = iterations
+		annealOpts.MaxIterations = iterations
+	}
+
 	fmt.Printf("---- Creating graph of size %d with average node degree < %.2f ----\n", N, D)
 	fmt.Printf("Seed: %v\n", seed)
 
@@ -232,9 +493,105 @@ func RunGraphColor() {
 		os.Exit(1)
 	}
 
-	graph := RandomGraph(N, "C", maxEdges, seed)
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	graph, warnings, err := RandomGraphContext(ctx, N, "C", maxEdges, seed)
+	for _, w := range warnings {
+		fmt.Println(w)
+	}
+	if err != nil {
+		fmt.Println("Unable to generate the graph:", err)
+		os.Exit(1)
+	}
+
+	if edges {
+		RunEdgeColor(graph, noPrint, noVisuals)
+		return
+	}
+
+	ordering, err := ParseVertexOrdering(orderingName)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
 
-	err := graph.Color(5, []string{"red", "green", "blue", "cyan", "orange"})
+	// Greedy coloring gives an upper bound for the number of colors
+	minimize := K == 0
+	if K == 0 {
+		_, bound, best := graph.ColorGreedyBest()
+		fmt.Printf("Greedy upper bound: %d colors with %v ordering\n", bound, best)
+		K = max(bound, 2)
+	}
+
+	if len(dimacs) > 0 {
+		if err := exportDIMACS(graph, K, dimacs); err != nil {
+			fmt.Println(err)
+		} else {
+			fmt.Printf("Saved the %d-coloring formula to %s\n", K, dimacs)
+		}
+	}
+
+	colors := ColorPalette(K)
+	var assignment ColorAssignment[string]
+	var trace *ColorTrace[string]
+
+	fmt.Printf("Coloring with algorithm: %s\n", algorithm)
+	switch algorithm {
+	case "backtrack":
+		observers := MultiObserver[string]{}
+		if !noPrint {
+			observers = append(observers, ConsoleObserver{os.Stdout})
+		}
+		if animate {
+			trace = &ColorTrace[string]{}
+			observers = append(observers, trace)
+		}
+		var observer ColorObserver = observers
+		assignment, err = graph.ColorObserved(ctx, len(colors), observer)
+	case "parallel":
+		fmt.Printf("Workers: %d\n", workers)
+		assignment, err = graph.ColorParallelContext(ctx, len(colors), workers, seed)
+	case "equitable":
+		assignment, err = graph.ColorEquitableContext(ctx, len(colors), seed)
+	case "balanced":
+		assignment, err = graph.ColorBalancedContext(ctx, len(colors), seed)
+	case "sat":
+		assignment, err = graph.ColorSATContext(ctx, len(colors), ColorConstraints[string]{})
+
+		// Without a given K, lower the bound until the formula becomes unsatisfiable
+		if minimize && err == nil {
+			var k int
+			assignment, k, err = graph.ColorMinimum(ctx, assignment, len(colors))
+			colors = colors[:k]
+			if err == nil {
+				fmt.Printf("Chromatic number: %d\n", k)
+			}
+		}
+	case "greedy":
+		fmt.Printf("Ordering: %v\n", ordering)
+		assignment, _, err = graph.ColorGreedyContext(ctx, ordering, seed)
+	case "tabu", "anneal":
+		var progress []ProgressPoint
+		if algorithm == "tabu" {
+			assignment, progress, err = graph.ColorTabuContext(ctx, len(colors), seed, tabuOpts)
+		} else {
+			assignment, progress, err = graph.ColorAnnealContext(ctx, len(colors), seed, annealOpts)
+		}
+		if !noPrint {
+			fmt.Println("Best conflicts over time:")
+			for _, p := range progress {
+				fmt.Printf("Iteration %d: %d conflicts\n", p.Iteration, p.Conflicts)
+			}
+		}
+	default:
+		fmt.Println("Not a recognized algorithm")
+		os.Exit(1)
+	}
 
 	if err != nil {
 		fmt.Println("Unable to color the graph:", err)
@@ -243,11 +600,17 @@ func RunGraphColor() {
 	isConnected, count := graph.Connected()
 	fmt.Println("Connected:", isConnected, "\nReachable:", count)
 
-	isColored, ncolors, conflicts := graph.Colored(5)
+	isColored, ncolors, conflicts := graph.ColoredWith(assignment, len(colors))
 	fmt.Println("Colored:", isColored, "\nColors used:", ncolors)
+	fmt.Println("Class sizes:", assignment.ClassSizes())
+
+	// Write the colors into the node values for printing and visuals
+	assignment.Apply(colors)
 
-	if !isColored && conflicts != nil {
+	if !isColored && len(conflicts) > 0 {
 		fmt.Println("There were conflicts with the coloring:\n", conflicts)
+	} else if !isColored {
+		fmt.Println("The coloring used too many colors")
 	} else {
 		fmt.Println("Successfully colored the graph")
 	}
@@ -282,7 +645,9 @@ func RunGraphColor() {
 		visualize := "n"
 		fmt.Println("Display the graph in a browser? (y/n)")
 		fmt.Scanf("%s\n", &visualize)
-		if visualize == "y" {
+		if visualize == "y" && trace != nil {
+			PlotColorTrace(graph, trace, colors)
+		} else if visualize == "y" {
 			PlotGraph(graph)
 		}
 	}
@@ -297,6 +662,32 @@ func RunGraphColor() {
 
 }
 
+// Save the SAT encoding of k-coloring the graph in DIMACS CNF format
+func exportDIMACS[T comparable](g *Graph[T], k int, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	f, _ := g.ColoringCNF(k, ColorConstraints[T]{})
+	return f.WriteDIMACS(file)
+}
+
+// Named colors for displaying a k-coloring, evenly spaced hues are used after the named ones run out
+func ColorPalette(k int) []string {
+	named := []string{"red", "green", "blue", "cyan", "orange", "purple", "yellow", "pink", "brown", "gray"}
+	colors := make([]string, k)
+	for i := range colors {
+		if i < len(named) {
+			colors[i] = named[i]
+		} else {
+			colors[i] = fmt.Sprintf("hsl(%d, 70%%, 50%%)", (i*137)%360)
+		}
+	}
+	return colors
+}
+
 // Excellent and simple online rendering tools for visualizing the graph data
 // https://csacademy.com/app/graph_editor/
 // https://mikhad.github.io/graph-builder/#2023
@@ -0,0 +1,157 @@
+// Exact graph coloring through a SAT reduction
+
+// Variable x(v, c) says node v has color c. Every node takes at least one and at most one color,
+// and the endpoints of every edge can't share a color. Colors are interchangeable, so the nodes of
+// a clique can be fixed to distinct colors up front without losing any solutions, which prunes
+// the symmetric parts of the search. The formula can be exported as DIMACS CNF for other solvers
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// The graph has no coloring with the requested number of colors
+var ErrNotColorable = errors.New("Graph is not colorable")
+
+// Find a clique greedily, starting from the node with the most neighbours
+func (g *Graph[T]) GreedyClique() []*Node[T] {
+	nodes := g.Nodes()
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return len(nodes[i].neighbours) > len(nodes[j].neighbours)
+	})
+
+	clique := make([]*Node[T], 0)
+	for _, n := range nodes {
+		adjacent := true
+		for _, m := range clique {
+			if !n.neighbours.Check(Edge[T]{n, m}) {
+				adjacent = false
+				break
+			}
+		}
+		if adjacent {
+			clique = append(clique, n)
+		}
+	}
+	return clique
+}
+
+// Encode k-coloring of the graph as a formula, x(v, c) is variable k*i + c + 1 for the ith node of the returned slice
+// Nodes of a greedy clique are fixed to distinct colors unless there are constraints
+func (g *Graph[T]) ColoringCNF(k int, constraints ColorConstraints[T]) (*CNF, []*Node[T]) {
+	nodes := g.Nodes()
+	index := make(map[*Node[T]]int, len(nodes))
+	for i, n := range nodes {
+		index[n] = i
+	}
+	x := func(i, c int) int {
+		return k*i + c + 1
+	}
+
+	f := &CNF{Vars: k * len(nodes)}
+	for i := range nodes {
+		atLeastOne := make([]int, k)
+		for c := 0; c < k; c++ {
+			atLeastOne[c] = x(i, c)
+			for d := c + 1; d < k; d++ {
+				f.Add(-x(i, c), -x(i, d))
+			}
+		}
+		f.Add(atLeastOne...)
+	}
+
+	for i, n := range nodes {
+		for _, e := range n.sortedEdges() {
+			if j := index[e.Other(n)]; j > i {
+				for c := 0; c < k; c++ {
+					f.Add(-x(i, c), -x(j, c))
+				}
+			}
+		}
+	}
+
+	for n, c := range constraints.Locked {
+		if c >= 0 && c < k {
+			f.Add(x(index[n], c))
+		} else {
+			f.Add()
+		}
+	}
+	for n, list := range constraints.Allowed {
+		for c := 0; c < k; c++ {
+			if !contains(list, c) {
+				f.Add(-x(index[n], c))
+			}
+		}
+	}
+
+	// Precolored nodes break the symmetry between colors already
+	if len(constraints.Locked) == 0 && len(constraints.Allowed) == 0 {
+		for c, n := range g.GreedyClique() {
+			if c >= k {
+				break
+			}
+			f.Add(x(index[n], c))
+		}
+	}
+
+	return f, nodes
+}
+
+// Color a graph G with exactly c colors or prove it can't be done
+// Returns ErrNotColorable if there is no such coloring
+func (g *Graph[T]) ColorSAT(c int) (ColorAssignment[T], error) {
+	return g.ColorSATContext(context.Background(), c, ColorConstraints[T]{})
+}
+
+// Color a graph G with c colors and constraints through SAT, stopping when the context is done
+func (g *Graph[T]) ColorSATContext(ctx context.Context, c int, constraints ColorConstraints[T]) (ColorAssignment[T], error) {
+
+	if g.root == nil {
+		return nil, errors.New("Graph is empty")
+	}
+	if err := constraints.validate(g, c); err != nil {
+		return nil, err
+	}
+
+	f, nodes := g.ColoringCNF(c, constraints)
+	sat, model, err := f.SolveContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !sat {
+		return nil, fmt.Errorf("%w with %d colors", ErrNotColorable, c)
+	}
+
+	assignment := make(ColorAssignment[T], len(nodes))
+	for i, n := range nodes {
+		for k := 0; k < c; k++ {
+			if model[c*i+k+1] {
+				assignment[n] = k
+				break
+			}
+		}
+	}
+	return assignment, nil
+}
+
+// Lower the number of colors of a c-coloring with SAT until it can't be lowered further
+// Returns the smallest coloring found and its number of colors, the chromatic number of the graph
+// On timeout the smallest coloring so far is returned with ErrTimeout
+func (g *Graph[T]) ColorMinimum(ctx context.Context, a ColorAssignment[T], c int) (ColorAssignment[T], int, error) {
+	for c > 1 {
+		smaller, err := g.ColorSATContext(ctx, c-1, ColorConstraints[T]{})
+		if errors.Is(err, ErrNotColorable) {
+			break
+		}
+		if err != nil {
+			return a, c, err
+		}
+		a, c = smaller, c-1
+	}
+	return a, c, nil
+}
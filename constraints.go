@@ -0,0 +1,242 @@
+// Coloring with precolored nodes and per-node lists of allowed colors
+
+// Constraints are checked for obvious contradictions before coloring, and the
+// conflict resolution and backtracking steps only ever choose allowed colors.
+// When no coloring exists, constraints are dropped until the remaining ones are
+// all needed for the contradiction, and the error names them
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Constraints on the colors of individual nodes, as color indexes
+type ColorConstraints[T comparable] struct {
+	Allowed map[*Node[T]][]int // Colors a node may take, any color if the node is missing
+	Locked  map[*Node[T]]int   // Precolored nodes that must keep their color
+}
+
+// A constraint that made the coloring impossible
+type ConstraintError[T comparable] struct {
+	Node   *Node[T]
+	Reason string
+}
+
+// Format a constraint error to string
+func (e *ConstraintError[T]) Error() string {
+	return fmt.Sprintf("Constraint on node %v: %s", e.Node, e.Reason)
+}
+
+// Check whether a node has any constraints
+func (cs ColorConstraints[T]) constrained(node *Node[T]) bool {
+	_, locked := cs.Locked[node]
+	_, listed := cs.Allowed[node]
+	return locked || listed
+}
+
+// Set of colors below c the node may take
+func (cs ColorConstraints[T]) allowed(node *Node[T], c int) map[int]struct{} {
+	possible := make(map[int]struct{})
+	if k, ok := cs.Locked[node]; ok {
+		if k >= 0 && k < c {
+			possible[k] = struct{}{}
+		}
+		return possible
+	}
+	if list, ok := cs.Allowed[node]; ok {
+		for _, k := range list {
+			if k >= 0 && k < c {
+				possible[k] = struct{}{}
+			}
+		}
+		return possible
+	}
+	for k := 0; k < c; k++ {
+		possible[k] = struct{}{}
+	}
+	return possible
+}
+
+// Colors below c the node may take in increasing order
+func (cs ColorConstraints[T]) ordered(node *Node[T], c int) []int {
+	colors := make([]int, 0)
+	for k := range cs.allowed(node, c) {
+		colors = append(colors, k)
+	}
+	sort.Ints(colors)
+	return colors
+}
+
+// Find constraints that can't be satisfied regardless of the other nodes
+// Nodes are checked in id order, so the same constraints always give the same error
+func (cs ColorConstraints[T]) validate(g *Graph[T], c int) error {
+	nodes := cs.nodes()
+	for _, node := range nodes {
+		k, ok := cs.Locked[node]
+		if !ok {
+			continue
+		}
+		if !g.nodes[node] {
+			return &ConstraintError[T]{node, "node is not in the graph"}
+		}
+		if k < 0 || k >= c {
+			return &ConstraintError[T]{node, fmt.Sprintf("locked color %d is out of range", k)}
+		}
+		if list, ok := cs.Allowed[node]; ok && !contains(list, k) {
+			return &ConstraintError[T]{node, fmt.Sprintf("locked color %d is not one of the allowed colors %v", k, list)}
+		}
+		for _, e := range node.sortedEdges() {
+			if other, ok := cs.Locked[e.Other(node)]; ok && other == k {
+				return &ConstraintError[T]{node, fmt.Sprintf("locked to color %d like its neighbour %v", k, e.Other(node))}
+			}
+		}
+	}
+
+	for _, node := range nodes {
+		list, ok := cs.Allowed[node]
+		if !ok {
+			continue
+		}
+		if !g.nodes[node] {
+			return &ConstraintError[T]{node, "node is not in the graph"}
+		}
+		// Colors of locked neighbours are never available
+		possible := cs.allowed(node, c)
+		if _, ok := cs.Locked[node]; !ok {
+			for e := range node.neighbours {
+				if k, ok := cs.Locked[e.Other(node)]; ok {
+					delete(possible, k)
+				}
+			}
+		}
+		if len(possible) < 1 {
+			return &ConstraintError[T]{node, fmt.Sprintf("no color left of the allowed colors %v after its locked neighbours", list)}
+		}
+	}
+	return nil
+}
+
+// Check whether a list contains color k
+func contains(list []int, k int) bool {
+	for _, v := range list {
+		if v == k {
+			return true
+		}
+	}
+	return false
+}
+
+// Color a graph G with max c colors, keeping locked nodes at their color and
+// only using allowed colors for listed nodes
+// Returns a ConstraintError if a constraint can be identified as the reason the coloring failed
+func (g *Graph[T]) ColorConstrained(c int, constraints ColorConstraints[T]) (ColorAssignment[T], error) {
+	return g.ColorConstrainedContext(context.Background(), c, constraints)
+}
+
+// Color a graph G with constraints, stopping when the context is done
+func (g *Graph[T]) ColorConstrainedContext(ctx context.Context, c int, constraints ColorConstraints[T]) (ColorAssignment[T], error) {
+
+	if err := constraints.validate(g, c); err != nil {
+		return nil, err
+	}
+
+	assignment, err := g.colorTree(ctx, c, constraints, nil)
+	if errors.Is(err, ErrNotColorable) && (len(constraints.Locked) > 0 || len(constraints.Allowed) > 0) {
+		return assignment, g.explainConstraints(ctx, c, constraints, err)
+	}
+	return assignment, err
+}
+
+// Constrained nodes in id order
+func (cs ColorConstraints[T]) nodes() []*Node[T] {
+	nodes := make([]*Node[T], 0, len(cs.Locked)+len(cs.Allowed))
+	for n := range cs.Locked {
+		nodes = append(nodes, n)
+	}
+	for n := range cs.Allowed {
+		if _, ok := cs.Locked[n]; !ok {
+			nodes = append(nodes, n)
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].id < nodes[j].id })
+	return nodes
+}
+
+// Copy of the constraints on the given nodes only
+func (cs ColorConstraints[T]) only(nodes []*Node[T]) ColorConstraints[T] {
+	kept := ColorConstraints[T]{make(map[*Node[T]][]int), make(map[*Node[T]]int)}
+	for _, n := range nodes {
+		if list, ok := cs.Allowed[n]; ok {
+			kept.Allowed[n] = list
+		}
+		if k, ok := cs.Locked[n]; ok {
+			kept.Locked[n] = k
+		}
+	}
+	return kept
+}
+
+// Describe the constraint on a node
+func (cs ColorConstraints[T]) describe(node *Node[T], c int) string {
+	if k, ok := cs.Locked[node]; ok {
+		return fmt.Sprintf("locked color %d", k)
+	}
+	return fmt.Sprintf("allowed colors %v", cs.ordered(node, c))
+}
+
+// Find the constraints that make a coloring with c colors impossible
+// Constraints are dropped one at a time as long as the rest still can't be satisfied, which leaves
+// a set where every constraint is needed for the contradiction. The first of them is reported
+func (g *Graph[T]) explainConstraints(ctx context.Context, c int, constraints ColorConstraints[T], cause error) error {
+
+	// Without constraints there is nothing to blame if the graph itself needs more colors
+	if _, err := g.colorExact(ctx, c, ColorConstraints[T]{}); err != nil {
+		return err
+	}
+
+	needed := constraints.nodes()
+	for i := 0; i < len(needed); {
+		rest := append(append([]*Node[T]{}, needed[:i]...), needed[i+1:]...)
+		_, err := g.colorExact(ctx, c, constraints.only(rest))
+		if errors.Is(err, ErrTimeout) {
+			return err
+		}
+		if err != nil {
+			needed = rest
+		} else {
+			i++
+		}
+	}
+	if len(needed) == 0 {
+		return cause
+	}
+
+	node := needed[0]
+	others := make([]string, 0, len(needed)-1)
+	for _, n := range needed[1:] {
+		others = append(others, fmt.Sprintf("%v with %s", n, constraints.describe(n, c)))
+	}
+	reason := fmt.Sprintf("%s can't be satisfied with %d colors", constraints.describe(node, c), c)
+	if len(others) > 0 {
+		reason += " together with " + strings.Join(others, ", ")
+	}
+	return &ConstraintError[T]{node, reason}
+}
+
+// Check whether a coloring honors the constraints, returns the first violated constraint in node id order
+func (g *Graph[T]) CheckConstraints(a ColorAssignment[T], constraints ColorConstraints[T]) error {
+	for _, node := range constraints.nodes() {
+		if k, ok := constraints.Locked[node]; ok && a[node] != k {
+			return &ConstraintError[T]{node, fmt.Sprintf("locked to color %d but colored %d", k, a[node])}
+		}
+		if list, ok := constraints.Allowed[node]; ok && !contains(list, a[node]) {
+			return &ConstraintError[T]{node, fmt.Sprintf("colored %d which is not one of the allowed colors %v", a[node], list)}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// Random connected graph with a known c-coloring found by SAT
+func coloredGraph(t *testing.T, r *rand.Rand, seed int64) (*Graph[int], ColorAssignment[int], int) {
+	t.Helper()
+	N := 4 + r.Intn(20)
+	g, _ := RandomGraph(N, 0, N+r.Intn(2*N), seed)
+	_, c, _ := g.ColorGreedyBest()
+	a, err := g.ColorSAT(c)
+	if err != nil {
+		t.Fatalf("Seed %d: %v", seed, err)
+	}
+	return g, a, c
+}
+
+// Check that the coloring is proper and honors the constraints
+func checkConstrained(t *testing.T, g *Graph[int], a ColorAssignment[int], c int, cs ColorConstraints[int]) {
+	t.Helper()
+	if ok, _, conflicts := g.ColoredWith(a, c); !ok {
+		t.Fatalf("Improper coloring with conflicts %v", conflicts)
+	}
+	if err := g.CheckConstraints(a, cs); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Expect a ConstraintError on one of the given nodes
+func expectConstraintError(t *testing.T, err error, nodes ...*Node[int]) *ConstraintError[int] {
+	t.Helper()
+	var ce *ConstraintError[int]
+	if !errors.As(err, &ce) {
+		t.Fatalf("Expected a constraint error, got %v", err)
+	}
+	for _, n := range nodes {
+		if ce.Node == n {
+			return ce
+		}
+	}
+	t.Fatalf("Constraint error names %v, expected one of %v", ce.Node, nodes)
+	return nil
+}
+
+func TestColorConstrainedPrecolored(t *testing.T) {
+	r := rand.New(rand.NewSource(10))
+	for seed := int64(1); seed <= 200; seed++ {
+		g, solution, c := coloredGraph(t, r, seed)
+
+		// Locking nodes to the colors of a known coloring keeps the instance feasible
+		cs := ColorConstraints[int]{Locked: make(map[*Node[int]]int)}
+		for _, n := range g.Nodes() {
+			if r.Intn(3) == 0 {
+				cs.Locked[n] = solution[n]
+			}
+		}
+		a, err := g.ColorConstrained(c, cs)
+		if err != nil {
+			t.Fatalf("Seed %d: feasible precoloring failed: %v", seed, err)
+		}
+		checkConstrained(t, g, a, c, cs)
+	}
+}
+
+func TestColorConstrainedLists(t *testing.T) {
+	r := rand.New(rand.NewSource(11))
+	for seed := int64(1); seed <= 200; seed++ {
+		g, solution, c := coloredGraph(t, r, seed)
+
+		// Every list holds the node's color in the known coloring plus some others
+		cs := ColorConstraints[int]{Allowed: make(map[*Node[int]][]int)}
+		for _, n := range g.Nodes() {
+			if r.Intn(2) == 0 {
+				continue
+			}
+			list := []int{solution[n]}
+			for k := 0; k < c; k++ {
+				if k != solution[n] && r.Intn(3) == 0 {
+					list = append(list, k)
+				}
+			}
+			cs.Allowed[n] = list
+		}
+		a, err := g.ColorConstrained(c, cs)
+		if err != nil {
+			t.Fatalf("Seed %d: feasible lists failed: %v", seed, err)
+		}
+		checkConstrained(t, g, a, c, cs)
+	}
+}
+
+func TestColorConstrainedLockedNeighbours(t *testing.T) {
+	g := NewGraph(3, 0)
+	n := g.Nodes()
+	g.Connect(Edge[int]{n[0], n[1]})
+	g.Connect(Edge[int]{n[1], n[2]})
+
+	_, err := g.ColorConstrained(3, ColorConstraints[int]{Locked: map[*Node[int]]int{n[0]: 1, n[1]: 1}})
+	expectConstraintError(t, err, n[0], n[1])
+
+	_, err = g.ColorConstrained(3, ColorConstraints[int]{Locked: map[*Node[int]]int{n[2]: 3}})
+	expectConstraintError(t, err, n[2])
+
+	// The middle node has no allowed color left after its locked neighbours
+	_, err = g.ColorConstrained(3, ColorConstraints[int]{
+		Locked:  map[*Node[int]]int{n[0]: 0, n[2]: 1},
+		Allowed: map[*Node[int]][]int{n[1]: {0, 1}},
+	})
+	expectConstraintError(t, err, n[1])
+}
+
+func TestColorConstrainedConflictingLists(t *testing.T) {
+	// A triangle can't be colored when all three nodes only allow two colors
+	g := CompleteGraph(3, 0)
+	n := g.Nodes()
+	cs := ColorConstraints[int]{Allowed: map[*Node[int]][]int{n[0]: {0, 1}, n[1]: {0, 1}, n[2]: {0, 1}}}
+
+	_, err := g.ColorConstrained(3, cs)
+	ce := expectConstraintError(t, err, n...)
+	if !strings.Contains(ce.Reason, "allowed colors [0 1]") {
+		t.Errorf("Reason %q doesn't name the allowed colors", ce.Reason)
+	}
+
+	// The constraints on other nodes of a larger graph are not to blame
+	g = NewGraph(6, 0)
+	n = g.Nodes()
+	for i := 0; i < 5; i++ {
+		n[i+1].value = i + 1
+		g.Connect(Edge[int]{n[i], n[i+1]})
+	}
+	g.Connect(Edge[int]{n[3], n[5]})
+	cs = ColorConstraints[int]{
+		Allowed: map[*Node[int]][]int{n[0]: {2}, n[3]: {0}, n[4]: {0, 1}, n[5]: {0, 1}},
+		Locked:  map[*Node[int]]int{n[1]: 1},
+	}
+	_, err = g.ColorConstrained(3, cs)
+	ce = expectConstraintError(t, err, n[3], n[4], n[5])
+	if strings.Contains(ce.Reason, "(0)") || strings.Contains(ce.Reason, "(1)") || strings.Contains(ce.Reason, "locked") {
+		t.Errorf("Reason %q names constraints that are not needed", ce.Reason)
+	}
+}
+
+func TestColorConstrainedNotColorable(t *testing.T) {
+	// The graph itself needs more colors, so no constraint is to blame
+	g := CompleteGraph(4, 0)
+	n := g.Nodes()
+	_, err := g.ColorConstrained(3, ColorConstraints[int]{Locked: map[*Node[int]]int{n[0]: 0}})
+	var ce *ConstraintError[int]
+	if errors.As(err, &ce) || !errors.Is(err, ErrNotColorable) {
+		t.Fatalf("Expected ErrNotColorable, got %v", err)
+	}
+}
+
+func TestColorConstrainedErrorOrder(t *testing.T) {
+	g := NewGraph(20, 0)
+	n := g.Nodes()
+	for i := 1; i < len(n); i++ {
+		g.Connect(Edge[int]{n[i-1], n[i]})
+	}
+
+	// Every constraint is broken, the node with the smallest id is always named
+	cs := ColorConstraints[int]{Locked: make(map[*Node[int]]int), Allowed: make(map[*Node[int]][]int)}
+	for i, node := range n[5:] {
+		if i%2 == 0 {
+			cs.Locked[node] = 7
+		} else {
+			cs.Allowed[node] = []int{}
+		}
+	}
+	for run := 0; run < 20; run++ {
+		_, err := g.ColorConstrained(3, cs)
+		if ce := expectConstraintError(t, err, n[5:]...); ce.Node != n[5] {
+			t.Fatalf("Run %d: error names node %d, expected node %d", run, ce.Node.id, n[5].id)
+		}
+	}
+}
@@ -0,0 +1,303 @@
+// Implementation of edge coloring algorithms
+
+// Edges sharing a node must get different colors. The Misra-Gries algorithm colors any graph
+// with at most D+1 colors where D is the maximum degree, by rotating fans of edges around a node
+// and swapping colors along alternating paths. Bipartite graphs can always be colored with D colors
+// (König's theorem) using only the alternating path swaps
+
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Assignment of color indexes to edges
+type EdgeColorAssignment[T comparable] map[Edge[T]]int
+
+// Maximum degree of the graph
+func (g *Graph[T]) MaxDegree() int {
+	degree := 0
+	for n := range g.nodes {
+		degree = max(degree, len(n.neighbours))
+	}
+	return degree
+}
+
+// Check whether the graph is bipartite, i.e. can be two-colored
+func (g *Graph[T]) Bipartite() bool {
+	_, _, adjacency := g.adjacencyLists()
+	side := make([]int, len(adjacency))
+	for start := range adjacency {
+		if side[start] != 0 {
+			continue
+		}
+		side[start] = 1
+		queue := []int{start}
+		for len(queue) > 0 {
+			i := queue[0]
+			queue = queue[1:]
+			for _, j := range adjacency[i] {
+				if side[j] == 0 {
+					side[j] = -side[i]
+					queue = append(queue, j)
+				} else if side[j] == side[i] {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// Edge colors of numbered nodes, at[i][c] is the neighbour of i through an edge with color c or -1
+type edgeColoring struct {
+	at [][]int
+}
+
+func newEdgeColoring(N, colors int) *edgeColoring {
+	ec := &edgeColoring{make([][]int, N)}
+	for i := range ec.at {
+		ec.at[i] = make([]int, colors)
+		for c := range ec.at[i] {
+			ec.at[i][c] = -1
+		}
+	}
+	return ec
+}
+
+func (ec *edgeColoring) free(i, c int) bool {
+	return ec.at[i][c] < 0
+}
+
+// Smallest color free at node i
+func (ec *edgeColoring) freeColor(i int) int {
+	for c := range ec.at[i] {
+		if ec.at[i][c] < 0 {
+			return c
+		}
+	}
+	return -1
+}
+
+// Color of the edge (i, j), -1 if uncolored
+func (ec *edgeColoring) color(i, j int) int {
+	for c, k := range ec.at[i] {
+		if k == j {
+			return c
+		}
+	}
+	return -1
+}
+
+func (ec *edgeColoring) set(i, j, c int) {
+	ec.at[i][c] = j
+	ec.at[j][c] = i
+}
+
+func (ec *edgeColoring) unset(i, j int) {
+	if c := ec.color(i, j); c >= 0 {
+		ec.at[i][c] = -1
+		ec.at[j][c] = -1
+	}
+}
+
+// Swap colors a and b on the alternating path starting from node i with an edge of color a
+func (ec *edgeColoring) invertPath(i, a, b int) {
+	type step struct{ i, j, c int }
+	path := make([]step, 0)
+	for c, other := a, b; ec.at[i][c] >= 0; c, other = other, c {
+		j := ec.at[i][c]
+		path = append(path, step{i, j, c})
+		i = j
+	}
+	for _, s := range path {
+		ec.unset(s.i, s.j)
+	}
+	for _, s := range path {
+		if s.c == a {
+			ec.set(s.i, s.j, b)
+		} else {
+			ec.set(s.i, s.j, a)
+		}
+	}
+}
+
+// Color the edges of a graph with at most D+1 colors using the Misra-Gries algorithm
+func (g *Graph[T]) ColorEdges() EdgeColorAssignment[T] {
+	nodes, index, adjacency := g.adjacencyLists()
+	colors := g.MaxDegree() + 1
+	ec := newEdgeColoring(len(nodes), colors)
+
+	for i := range adjacency {
+		for _, v := range adjacency[i] {
+			if v < i {
+				continue
+			}
+			u := i
+
+			// Build a maximal fan of u starting from v
+			fan := []int{v}
+			inFan := map[int]bool{v: true}
+			for grown := true; grown; {
+				grown = false
+				last := fan[len(fan)-1]
+				for c := 0; c < colors; c++ {
+					w := ec.at[u][c]
+					if ec.free(last, c) && w >= 0 && !inFan[w] {
+						fan = append(fan, w)
+						inFan[w] = true
+						grown = true
+						break
+					}
+				}
+			}
+
+			// Free colors on u and the end of the fan
+			c := ec.freeColor(u)
+			d := ec.freeColor(fan[len(fan)-1])
+			ec.invertPath(u, d, c)
+
+			// Find the shortest prefix of the fan that is still a fan and ends with d free
+			w := 0
+			for k := range fan {
+				if k > 0 && !ec.free(fan[k-1], ec.color(u, fan[k])) {
+					break
+				}
+				w = k
+				if ec.free(fan[k], d) {
+					break
+				}
+			}
+
+			// Rotate the fan prefix and color the last edge with d
+			for k := 0; k < w; k++ {
+				next := ec.color(u, fan[k+1])
+				ec.unset(u, fan[k+1])
+				ec.set(u, fan[k], next)
+			}
+			ec.set(u, fan[w], d)
+		}
+	}
+
+	return edgeAssignment(g, index, ec)
+}
+
+// Color the edges of a bipartite graph with exactly D colors
+func (g *Graph[T]) ColorEdgesBipartite() (EdgeColorAssignment[T], error) {
+	if !g.Bipartite() {
+		return nil, errors.New("Graph is not bipartite")
+	}
+
+	nodes, index, adjacency := g.adjacencyLists()
+	ec := newEdgeColoring(len(nodes), g.MaxDegree())
+
+	for u := range adjacency {
+		for _, v := range adjacency[u] {
+			if v < u {
+				continue
+			}
+			a, b := ec.freeColor(u), ec.freeColor(v)
+			if !ec.free(v, a) {
+				// The a/b path from v can't reach u in a bipartite graph, so swapping it frees a at v
+				ec.invertPath(v, a, b)
+			}
+			ec.set(u, v, a)
+		}
+	}
+
+	return edgeAssignment(g, index, ec), nil
+}
+
+// Convert an edge coloring of numbered nodes to an assignment
+func edgeAssignment[T comparable](g *Graph[T], index map[*Node[T]]int, ec *edgeColoring) EdgeColorAssignment[T] {
+	assignment := make(EdgeColorAssignment[T], len(g.edges))
+	for e := range g.edges {
+		assignment[e] = ec.color(index[e.a], index[e.b])
+	}
+	return assignment
+}
+
+// Check whether an edge coloring uses max m colors with no two edges of a node sharing a color
+// Returns the amount of colors used and the nodes where edge colors conflict
+func (g *Graph[T]) EdgeColored(a EdgeColorAssignment[T], m int) (bool, int, NodeSet[T]) {
+	colors := make(map[int]bool)
+	conflicts := make(NodeSet[T])
+
+	for n := range g.nodes {
+		seen := make(map[int]bool)
+		for e := range n.neighbours {
+			c, ok := a[e]
+			if !ok || c < 0 || seen[c] {
+				conflicts[n] = true
+			}
+			seen[c] = true
+			colors[c] = true
+		}
+	}
+	return len(conflicts) == 0 && len(colors) <= m, len(colors), conflicts
+}
+
+// Program part to demonstrate edge coloring on a generated graph
+func RunEdgeColor(graph *Graph[string], noPrint bool, noVisuals bool) {
+	var assignment EdgeColorAssignment[string]
+	var err error
+
+	degree := graph.MaxDegree()
+	limit := degree + 1
+	if graph.Bipartite() {
+		fmt.Println("Graph is bipartite, coloring edges with König's theorem")
+		assignment, err = graph.ColorEdgesBipartite()
+		limit = degree
+	} else {
+		fmt.Println("Coloring edges with the Misra-Gries algorithm")
+		assignment = graph.ColorEdges()
+	}
+
+	if err != nil {
+		fmt.Println("Unable to color the edges:", err)
+		return
+	}
+
+	isColored, ncolors, conflicts := graph.EdgeColored(assignment, limit)
+	fmt.Println("Maximum degree:", degree, "\nEdge colored:", isColored, "\nColors used:", ncolors)
+
+	if !isColored && len(conflicts) > 0 {
+		fmt.Println("There were conflicts at nodes:\n", conflicts)
+	} else if !isColored {
+		fmt.Println("The coloring used too many colors")
+	} else {
+		fmt.Println("Successfully colored the edges")
+	}
+
+	used := 0
+	for _, c := range assignment {
+		used = max(used, c+1)
+	}
+	palette := ColorPalette(used)
+	linkColors := make(map[Edge[string]]string, len(assignment))
+	for e, c := range assignment {
+		if c >= 0 && c < len(palette) {
+			linkColors[e] = palette[c]
+		}
+	}
+
+	if noPrint {
+		fmt.Println("--noprint specified, skip edge printing")
+	} else {
+		for e, c := range linkColors {
+			fmt.Printf("%v %v %s\n", e.a, e.b, c)
+		}
+	}
+
+	if noVisuals {
+		fmt.Println("--novisuals specified")
+	} else {
+		visualize := "n"
+		fmt.Println("Display the graph in a browser? (y/n)")
+		fmt.Scanf("%s\n", &visualize)
+		if visualize == "y" {
+			PlotEdgeColoring(graph, linkColors)
+		}
+	}
+}
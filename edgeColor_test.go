@@ -0,0 +1,97 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// Random bipartite graph with sides of a and b nodes where each pair across is connected with probability p
+func randomBipartiteGraph(r *rand.Rand, a, b int, p float64) *Graph[int] {
+	g := NewGraph(a+b, 0)
+	nodes := g.Nodes()
+	for _, u := range nodes[:a] {
+		for _, v := range nodes[a:] {
+			if r.Float64() < p {
+				g.Connect(Edge[int]{u, v})
+			}
+		}
+	}
+	return g
+}
+
+// Check that every edge has a color in 0..m-1 and the coloring is proper
+func checkEdgeColoring(t *testing.T, g *Graph[int], a EdgeColorAssignment[int], m int) int {
+	t.Helper()
+	if len(a) != len(g.edges) {
+		t.Fatalf("%d of %d edges colored", len(a), len(g.edges))
+	}
+	for e, c := range a {
+		if c < 0 || c >= m {
+			t.Fatalf("Edge %v has color %d, expected one of %d", e, c, m)
+		}
+	}
+	ok, used, conflicts := g.EdgeColored(a, m)
+	if !ok {
+		t.Fatalf("Improper edge coloring with %d colors, conflicts at %v", used, conflicts)
+	}
+	return used
+}
+
+func TestColorEdgesRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(40))
+	for seed := int64(1); seed <= 300; seed++ {
+		var g *Graph[int]
+		if seed%2 == 0 {
+			N := 2 + r.Intn(40)
+			g, _ = RandomGraph(N, 0, N+r.Intn(3*N), seed)
+		} else {
+			g = randomDenseGraph(r, 2+r.Intn(20), r.Float64())
+		}
+
+		// Vizing's theorem, Misra-Gries never needs more than D+1 colors
+		D := g.MaxDegree()
+		used := checkEdgeColoring(t, g, g.ColorEdges(), D+1)
+		if used < D {
+			t.Fatalf("Seed %d: %d colors for maximum degree %d", seed, used, D)
+		}
+	}
+}
+
+func TestColorEdgesBipartite(t *testing.T) {
+	r := rand.New(rand.NewSource(41))
+	for seed := int64(1); seed <= 300; seed++ {
+		g := randomBipartiteGraph(r, 1+r.Intn(15), 1+r.Intn(15), r.Float64())
+		a, err := g.ColorEdgesBipartite()
+		if err != nil {
+			t.Fatalf("Seed %d: %v", seed, err)
+		}
+
+		// König's theorem, the edges of a bipartite graph need exactly D colors
+		D := g.MaxDegree()
+		if used := checkEdgeColoring(t, g, a, max(D, 1)); used != D {
+			t.Fatalf("Seed %d: %d colors for maximum degree %d", seed, used, D)
+		}
+	}
+}
+
+func TestColorEdgesBipartiteRejectsOddCycle(t *testing.T) {
+	if _, err := CompleteGraph(3, 0).ColorEdgesBipartite(); err == nil {
+		t.Fatal("Expected an error for a triangle")
+	}
+}
+
+func TestEdgeColoredConflicts(t *testing.T) {
+	g := NewGraph(3, 0)
+	n := g.Nodes()
+	ab, bc := Edge[int]{n[0], n[1]}, Edge[int]{n[1], n[2]}
+	g.Connect(ab)
+	g.Connect(bc)
+
+	ok, _, conflicts := g.EdgeColored(EdgeColorAssignment[int]{ab: 0, bc: 0}, 2)
+	if ok || !conflicts[n[1]] || conflicts[n[0]] || conflicts[n[2]] {
+		t.Fatalf("Shared color at the middle node reported as %v with conflicts %v", ok, conflicts)
+	}
+	if ok, _, conflicts := g.EdgeColored(EdgeColorAssignment[int]{ab: 0}, 2); ok || !conflicts[n[1]] || !conflicts[n[2]] {
+		t.Fatalf("Uncolored edge reported as %v with conflicts %v", ok, conflicts)
+	}
+}
@@ -0,0 +1,162 @@
+// Equitable and balanced graph coloring
+
+// Starting from a greedy coloring, nodes are moved between color classes without creating conflicts.
+// Moves from a class to one at least two smaller always reduce the variance of the class sizes.
+// When those run out, moves between classes differing by one shuffle the classes around so
+// new improving moves can open up. Equitable colorings need class sizes differing by at most one,
+// balanced colorings just keep the smallest variance found.
+// By the Hajnal-Szemerédi theorem an equitable k-coloring exists whenever k is larger than the
+// maximum degree, but this search is a heuristic and can still fail to find one
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+)
+
+// Number of nodes with each color
+func (a ColorAssignment[T]) ClassSizes() []int {
+	sizes := make([]int, 0)
+	for _, c := range a {
+		for c >= len(sizes) {
+			sizes = append(sizes, 0)
+		}
+		sizes[c]++
+	}
+	return sizes
+}
+
+// Color a graph G with k colors so that the color class sizes differ by at most one
+// The search may fail even when an equitable coloring exists, the best coloring found is returned with the error
+func (g *Graph[T]) ColorEquitable(k int, seed int64) (ColorAssignment[T], error) {
+	return g.ColorEquitableContext(context.Background(), k, seed)
+}
+
+// Color a graph G equitably, stopping when the context is done
+// On timeout, the most balanced coloring so far is returned with ErrTimeout
+func (g *Graph[T]) ColorEquitableContext(ctx context.Context, k int, seed int64) (ColorAssignment[T], error) {
+	assignment, sizes, err := g.balance(ctx, k, seed, true)
+	if err != nil {
+		return assignment, err
+	}
+	if spread(sizes) > 1 {
+		return assignment, fmt.Errorf("Could not find an equitable coloring, class sizes %v", sizes)
+	}
+	return assignment, nil
+}
+
+// Color a graph G with k colors, minimizing the variance of the color class sizes
+func (g *Graph[T]) ColorBalanced(k int, seed int64) (ColorAssignment[T], error) {
+	return g.ColorBalancedContext(context.Background(), k, seed)
+}
+
+// Color a graph G with balanced class sizes, stopping when the context is done
+// On timeout, the most balanced coloring so far is returned with ErrTimeout
+func (g *Graph[T]) ColorBalancedContext(ctx context.Context, k int, seed int64) (ColorAssignment[T], error) {
+	assignment, _, err := g.balance(ctx, k, seed, false)
+	return assignment, err
+}
+
+// Difference of the largest and smallest class
+func spread(sizes []int) int {
+	lo, hi := sizes[0], sizes[0]
+	for _, s := range sizes {
+		lo, hi = min(lo, s), max(hi, s)
+	}
+	return hi - lo
+}
+
+// Sum of squared class sizes, smaller means more balanced
+func squares(sizes []int) int {
+	sum := 0
+	for _, s := range sizes {
+		sum += s * s
+	}
+	return sum
+}
+
+// Balance the class sizes of a k-coloring, stopping early at an equitable coloring if requested
+func (g *Graph[T]) balance(ctx context.Context, k int, seed int64, equitable bool) (ColorAssignment[T], []int, error) {
+
+	if g.root == nil {
+		return nil, nil, errors.New("Graph is empty")
+	}
+
+	// Start from any proper k-coloring
+	start, used, _ := g.ColorGreedyBest()
+	if used > k {
+		var err error
+		if start, _, err = g.ColorTabuContext(ctx, k, seed, DefaultTabuOptions()); err != nil {
+			// Without a proper coloring to balance, return the best one tabu search found
+			return start, start.ClassSizes(), err
+		}
+	}
+
+	nodes, index, adjacency := g.adjacencyLists()
+	colors := make([]int, len(nodes))
+	for n, c := range start {
+		colors[index[n]] = c
+	}
+	s := newConflictState(adjacency, k, colors)
+	sizes := make([]int, k)
+	for _, c := range colors {
+		sizes[c]++
+	}
+
+	r := rand.New(rand.NewSource(seed))
+	best := append([]int(nil), colors...)
+	bestSizes := append([]int(nil), sizes...)
+
+	// Nodes that made a sideways move can't move sideways again until this pass
+	recent := make([]int, len(nodes))
+	maxPasses := 100 + 10*k
+
+	var err error
+	for pass := 1; pass <= maxPasses; pass++ {
+		if equitable && spread(bestSizes) <= 1 {
+			break
+		}
+		if ctx.Err() != nil {
+			err = timeoutError(ctx)
+			break
+		}
+
+		improved := false
+		for _, i := range r.Perm(len(nodes)) {
+			a := colors[i]
+			for b := 0; b < k; b++ {
+				// Only move to classes without neighbours, so the coloring stays proper
+				if b == a || s.gamma[i][b] > 0 {
+					continue
+				}
+				if sizes[a] > sizes[b]+1 || (sizes[a] == sizes[b]+1 && recent[i] < pass && r.Intn(4) == 0) {
+					if sizes[a] == sizes[b]+1 {
+						recent[i] = pass + 5
+					} else {
+						improved = true
+					}
+					s.move(i, b)
+					sizes[a]--
+					sizes[b]++
+					break
+				}
+			}
+		}
+
+		if squares(sizes) < squares(bestSizes) {
+			copy(best, colors)
+			copy(bestSizes, sizes)
+		} else if !improved && spread(sizes) <= 1 {
+			break
+		}
+	}
+
+	assignment := make(ColorAssignment[T], len(nodes))
+	for i, n := range nodes {
+		assignment[n] = best[i]
+	}
+	return assignment, bestSizes, err
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// Size of every color class of a k-coloring, including empty ones
+func classSizes[T comparable](a ColorAssignment[T], k int) []int {
+	sizes := make([]int, k)
+	for _, c := range a {
+		sizes[c]++
+	}
+	return sizes
+}
+
+func TestColorEquitable(t *testing.T) {
+	r := rand.New(rand.NewSource(30))
+	for seed := int64(1); seed <= 200; seed++ {
+		N := 5 + r.Intn(40)
+		g, _ := RandomGraph(N, 0, N+r.Intn(2*N), seed)
+
+		// An equitable coloring exists with more colors than the maximum degree
+		k := g.MaxDegree() + 1 + r.Intn(3)
+		a, err := g.ColorEquitable(k, seed)
+		if err != nil {
+			t.Fatalf("Seed %d: equitable coloring with %d colors failed: %v", seed, k, err)
+		}
+		if ok, _, conflicts := g.ColoredWith(a, k); !ok {
+			t.Fatalf("Seed %d: improper coloring with conflicts %v", seed, conflicts)
+		}
+		if sizes := classSizes(a, k); spread(sizes) > 1 {
+			t.Fatalf("Seed %d: class sizes %v differ by more than one", seed, sizes)
+		}
+	}
+}
+
+func TestColorBalanced(t *testing.T) {
+	r := rand.New(rand.NewSource(31))
+	for seed := int64(1); seed <= 200; seed++ {
+		N := 5 + r.Intn(40)
+		g, _ := RandomGraph(N, 0, N+r.Intn(3*N), seed)
+		start, used, _ := g.ColorGreedyBest()
+		k := used + r.Intn(3)
+
+		a, err := g.ColorBalanced(k, seed)
+		if err != nil {
+			t.Fatalf("Seed %d: balanced coloring with %d colors failed: %v", seed, k, err)
+		}
+		if ok, _, conflicts := g.ColoredWith(a, k); !ok {
+			t.Fatalf("Seed %d: improper coloring with conflicts %v", seed, conflicts)
+		}
+
+		// Balancing starts from the greedy coloring and never increases the variance
+		if sizes, before := classSizes(a, k), classSizes(start, k); squares(sizes) > squares(before) {
+			t.Fatalf("Seed %d: class sizes %v are less balanced than the greedy %v", seed, sizes, before)
+		}
+	}
+}
+
+func TestColorBalancedStar(t *testing.T) {
+	// The center of a star takes a class of its own, the ten leaves split evenly over the others
+	g := NewGraph(11, 0)
+	nodes := g.Nodes()
+	for _, leaf := range nodes[1:] {
+		g.Connect(Edge[int]{nodes[0], leaf})
+	}
+
+	a, err := g.ColorBalanced(3, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, _, _ := g.ColoredWith(a, 3); !ok {
+		t.Fatal("Improper coloring of the star")
+	}
+	if sizes := classSizes(a, 3); squares(sizes) != 1+25+25 {
+		t.Fatalf("Class sizes %v, expected the center alone and two classes of five", sizes)
+	}
+
+	// Sizes 1, 5 and 5 are the most balanced, but not equitable
+	if _, err := g.ColorEquitable(3, 1); err == nil {
+		t.Fatal("Expected equitable coloring of the star to fail")
+	}
+}
+
+func TestColorEquitableTooFewColors(t *testing.T) {
+	// Tabu search can't 3-color K5, its best attempt is returned with the error
+	g := CompleteGraph(5, 0)
+	a, err := g.ColorEquitable(3, 1)
+	if err == nil {
+		t.Fatal("Expected coloring K5 with 3 colors to fail")
+	}
+	if len(a) != 5 {
+		t.Fatalf("Expected the best coloring found with the error, got %d nodes", len(a))
+	}
+}
@@ -68,11 +68,11 @@ func RunLocalMinimum() {
 	for i, v := range os.Args {
 		switch v {
 		case "-N", "--N":
-			SScanInt(os.Args[i+1], &N, "N")
+			SScanInt(ArgValue(i), &N, "N")
 		case "-S", "--S":
-			SScanInt(os.Args[i+1], &intSeed, "seed")
+			SScanInt(ArgValue(i), &intSeed, "seed")
 		case "-O", "--O":
-			fmt.Sscanf(os.Args[i+1], "%s", &Out)
+			fmt.Sscanf(ArgValue(i), "%s", &Out)
 		case "--noprint":
 			noPrint = true
 		case "--novisuals":
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func FuzzParseGraphJson(f *testing.F) {
+	f.Add([]byte(`[{"A":1,"B":2,"AVal":"x","BVal":"y"},{"A":2,"B":3,"AVal":"y","BVal":"z"}]`))
+	f.Add([]byte(`[]`))
+	f.Add([]byte(`null`))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// Keep each run fast, large inputs only repeat what small ones cover
+		if len(data) > 4096 {
+			t.Skip()
+		}
+		g, err := ParseGraphJson[string](data)
+		if err != nil {
+			return
+		}
+		checkConsistent(t, g)
+		for e := range g.edges {
+			if e.a == e.b {
+				t.Fatal("Parsed graph has a self loop")
+			}
+		}
+
+		// Saving and parsing again gives the same graph
+		out, err := json.Marshal(g.JsonEdges())
+		if err != nil {
+			t.Fatal(err)
+		}
+		again, err := ParseGraphJson[string](out)
+		if err != nil {
+			t.Fatalf("Failed to parse saved graph: %v", err)
+		}
+		if again.CanonicalForm() != g.CanonicalForm() {
+			t.Fatal("Saved graph differs after parsing it again")
+		}
+	})
+}
+
+func FuzzDecodeInputImage(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		n, cells, err := DecodeInputImage(bytes.NewReader(data))
+		if err != nil {
+			return
+		}
+		if n <= 0 || n > maxInputSide || len(cells) != n*n {
+			t.Fatalf("Decoded side %d with %d cells", n, len(cells))
+		}
+	})
+}
+
+func FuzzParseInt(f *testing.F) {
+	for _, s := range []string{"1", "42", " 7 ", "0", "-3", "1e3", "0x10", "99999999999999999999", ""} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		i, err := ParseInt(s, "param")
+		if err != nil {
+			return
+		}
+		if i <= 0 {
+			t.Fatalf("Accepted %d from %q", i, s)
+		}
+		if j, err := ParseInt(strconv.Itoa(i), "param"); err != nil || j != i {
+			t.Fatalf("Read %d from %q but not from its decimal form", i, s)
+		}
+	})
+}
+
+func FuzzParseFloat(f *testing.F) {
+	for _, s := range []string{"1", "0.5", "3e2", "0", "-1", "NaN", "Inf", "+Inf", "1e400", "0x1p-2", ""} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		v, err := ParseFloat(s, "param")
+		if err != nil {
+			return
+		}
+		if !(v > 0) || math.IsInf(v, 0) {
+			t.Fatalf("Accepted %v from %q", v, s)
+		}
+	})
+}
+
+func FuzzParseDuration(f *testing.F) {
+	for _, s := range []string{"10s", "1m30s", "500ms", "0s", "-1s", "1", ""} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		d, err := ParseDuration(s, "timeout")
+		if err != nil {
+			return
+		}
+		if d <= 0 {
+			t.Fatalf("Accepted %v from %q", d, s)
+		}
+	})
+}
+
+func FuzzFlagValue(f *testing.F) {
+	f.Add("-N 10 -D 3", 0)
+	f.Add("-N", 0)
+	f.Add("-N 10", 1)
+	f.Add("", 0)
+	f.Add("-I in.json", -1)
+	f.Fuzz(func(t *testing.T, line string, i int) {
+		args := strings.Fields(line)
+		v, err := FlagValue(args, i)
+		if err != nil {
+			return
+		}
+		if i < 0 || i+1 >= len(args) || v != args[i+1] {
+			t.Fatalf("Value %q at index %d of %q", v, i, args)
+		}
+	})
+}
@@ -0,0 +1,60 @@
+// Generators for graphs with a known structure
+
+// Latin squares and sudokus are colorings of grid graphs: every cell is a node, and the
+// cells that must hold different symbols, those sharing a row, column or box, are connected
+
+package main
+
+// Connect every pair of the given nodes
+func (g *Graph[T]) connectClique(nodes []*Node[T]) {
+	for i, a := range nodes {
+		for _, b := range nodes[i+1:] {
+			g.Connect(Edge[T]{a, b})
+		}
+	}
+}
+
+// Create the complete graph on N nodes
+func CompleteGraph[T comparable](N int, value T) *Graph[T] {
+	g := NewGraph(N, value)
+	g.connectClique(g.Nodes())
+	return g
+}
+
+// Create an n x n grid of nodes where the cells of each row and column form a clique
+// The n-colorings are exactly the Latin squares of order n
+// Returns the graph and its nodes by row and column
+func LatinSquareGraph[T comparable](n int, value T) (*Graph[T], [][]*Node[T]) {
+	g := EmptyGraph[T]()
+	cells := make([][]*Node[T], n)
+	for r := range cells {
+		cells[r] = g.AddNodes(n, value)
+	}
+
+	for i := 0; i < n; i++ {
+		column := make([]*Node[T], n)
+		for r := range cells {
+			column[r] = cells[r][i]
+		}
+		g.connectClique(cells[i])
+		g.connectClique(column)
+	}
+	return g, cells
+}
+
+// Create the sudoku graph with boxes of size box x box, a Latin square graph of order box*box
+// where the cells of each box also form a clique
+// Returns the graph and its nodes by row and column
+func SudokuGraph[T comparable](box int, value T) (*Graph[T], [][]*Node[T]) {
+	g, cells := LatinSquareGraph(box*box, value)
+	for br := 0; br < box; br++ {
+		for bc := 0; bc < box; bc++ {
+			nodes := make([]*Node[T], 0, box*box)
+			for r := br * box; r < (br+1)*box; r++ {
+				nodes = append(nodes, cells[r][bc*box:(bc+1)*box]...)
+			}
+			g.connectClique(nodes)
+		}
+	}
+	return g, cells
+}
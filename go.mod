@@ -2,4 +2,4 @@ module go-algorithms
 
 go 1.23.2
 
-require github.com/go-echarts/go-echarts/v2 v2.4.3 // indirect
+require github.com/go-echarts/go-echarts/v2 v2.4.3
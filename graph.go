@@ -4,15 +4,19 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"math/rand"
 	"os"
+	"sort"
 	"strings"
 )
 
 // A node has a value and neighbours
+// The id numbers nodes in the order they were added to the graph
 type Node[T comparable] struct {
+	id         int
 	value      T
 	neighbours EdgeSet[T]
 }
@@ -31,6 +35,7 @@ type Graph[T comparable] struct {
 	nodes NodeSet[T]
 	edges EdgeSet[T]
 	root  *Node[T]
+	next  int // Id for the next added node
 }
 
 // Check for edge (a, b) or (b, a)
@@ -48,6 +53,14 @@ func (e EdgeSet[T]) Add(eg Edge[T]) {
 	}
 }
 
+// Get the node on the other end of the edge
+func (e Edge[T]) Other(n *Node[T]) *Node[T] {
+	if n != e.a {
+		return e.a
+	}
+	return e.b
+}
+
 // Add a new edge to a graph
 func (g *Graph[T]) Connect(eg Edge[T]) {
 	g.nodes[eg.a] = true
@@ -70,13 +83,13 @@ func (g *Graph[T]) Disconnect(eg Edge[T]) {
 
 // Initialize an empty graph
 func EmptyGraph[T comparable]() *Graph[T] {
-	g := Graph[T]{make(NodeSet[T]), make(EdgeSet[T]), nil}
+	g := Graph[T]{make(NodeSet[T]), make(EdgeSet[T]), nil, 0}
 	return &g
 }
 
 // Initialize a graph with N nodes
 func NewGraph[T comparable](N int, value T) *Graph[T] {
-	g := Graph[T]{make(NodeSet[T], N), make(EdgeSet[T]), nil}
+	g := Graph[T]{make(NodeSet[T], N), make(EdgeSet[T]), nil, 0}
 	g.AddNodes(N, value)
 	return &g
 }
@@ -85,7 +98,8 @@ func NewGraph[T comparable](N int, value T) *Graph[T] {
 func (g *Graph[T]) AddNodes(N int, value T) []*Node[T] {
 	newNodes := make([]*Node[T], N)
 	for i := range newNodes {
-		node := &Node[T]{value, make(EdgeSet[T])}
+		node := &Node[T]{g.next, value, make(EdgeSet[T])}
+		g.next++
 		newNodes[i] = node
 		g.nodes[node] = true
 		if g.root == nil {
@@ -104,6 +118,34 @@ func (g *Graph[T]) AddNodesToParent(N int, value T, parent *Node[T]) []*Node[T]
 	return newNodes
 }
 
+// List the nodes of the graph in the order they were added
+func (g *Graph[T]) Nodes() []*Node[T] {
+	nodes := make([]*Node[T], 0, len(g.nodes))
+	for n := range g.nodes {
+		nodes = append(nodes, n)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].id < nodes[j].id })
+	return nodes
+}
+
+// Number the nodes in the order they were added and list the neighbours of each node by number
+func (g *Graph[T]) adjacencyLists() ([]*Node[T], map[*Node[T]]int, [][]int) {
+	nodes := g.Nodes()
+	index := make(map[*Node[T]]int, len(nodes))
+	for i, n := range nodes {
+		index[n] = i
+	}
+	adjacency := make([][]int, len(nodes))
+	for i, n := range nodes {
+		adjacency[i] = make([]int, 0, len(n.neighbours))
+		for e := range n.neighbours {
+			adjacency[i] = append(adjacency[i], index[e.Other(n)])
+		}
+		sort.Ints(adjacency[i])
+	}
+	return nodes, index, adjacency
+}
+
 // Format a node to string
 func (n *Node[T]) String() string {
 	return fmt.Sprintf("(%v)", n.value)
@@ -162,53 +204,94 @@ func (g *Graph[T]) Stats() {
 	fmt.Printf("Number of nodes: %d, number of edges: %d, average degree: %.2f\n", len(g.nodes), len(g.edges), 2.0*float64(len(g.edges))/float64(len(g.nodes)))
 }
 
-// Builds a random graph by first creating a spanning tree,
+// Builds a random graph by first creating a spanning tree in node id order,
 // then adding random edges until the desired amount is reached
-func RandomGraph[T comparable](N int, value T, maxEdges int, seed int64) *Graph[T] {
+// Returns warnings when the edge count had to be adjusted to fit N nodes
+func RandomGraph[T comparable](N int, value T, maxEdges int, seed int64) (*Graph[T], []string) {
+	graph, warnings, _ := RandomGraphContext(context.Background(), N, value, maxEdges, seed)
+	return graph, warnings
+}
+
+// Build a random graph, stopping when the context is done
+// The graph has exactly maxEdges edges, at least the N-1 of a spanning tree and at most the N(N-1)/2 of the complete graph
+// On timeout, the graph built so far is returned with ErrTimeout
+func RandomGraphContext[T comparable](ctx context.Context, N int, value T, maxEdges int, seed int64) (*Graph[T], []string, error) {
 
 	edgeCount := 0
+	warnings := make([]string, 0)
 	r := rand.New(rand.NewSource(seed))
 
 	graph := NewGraph(N, value)
+	if N < 1 {
+		return graph, warnings, nil
+	}
+
+	complete := N * (N - 1) / 2
+	if maxEdges > complete {
+		warnings = append(warnings, fmt.Sprintf("Requested %d edges, the complete graph on %d nodes has %d", maxEdges, N, complete))
+		maxEdges = complete
+	}
+	if maxEdges < N-1 {
+		warnings = append(warnings, fmt.Sprintf("Requested %d edges, connecting %d nodes takes %d, generated a minimally connected tree", maxEdges, N, N-1))
+		maxEdges = N - 1
+	}
+
 	group := make([]*Node[T], 0, N)
 	connected := make(NodeSet[T])
 
 	connected[graph.root] = true
 	group = append(group, graph.root)
 
-	for k := range graph.nodes {
+	// Visit the nodes in id order so the same seed always builds the same tree
+	for _, k := range graph.Nodes() {
+		if connected[k] {
+			continue
+		}
+		if edgeCount%contextCheckInterval == 0 && ctx.Err() != nil {
+			return graph, warnings, timeoutError(ctx)
+		}
 		choice := group[r.Intn(len(group))] // Random connection to the connected graph
 		graph.Connect(Edge[T]{k, choice})
 		edgeCount++
-		if !connected[k] {
-			connected[k] = true
-			group = append(group, k)
-		}
-		if !connected[choice] {
-			connected[choice] = true
-			group = append(group, choice)
-		}
+		connected[k] = true
+		group = append(group, k)
 	}
 
-	if edgeCount >= maxEdges {
-		// We exceeded maxedges by making a connected graph, meaning it has average degree 2
-		fmt.Println("Minimally connected tree")
+	missing := maxEdges - edgeCount
+	if 2*missing > complete-edgeCount {
+		// Most of the remaining pairs get connected, so pick them from a list of all unconnected pairs
+		pairs := make([]Edge[T], 0, complete-edgeCount)
+		for i, a := range group {
+			for _, b := range group[i+1:] {
+				if !graph.edges.Check(Edge[T]{a, b}) {
+					pairs = append(pairs, Edge[T]{a, b})
+				}
+			}
+		}
+		for i := 0; i < missing; i++ {
+			if i%contextCheckInterval == 0 && ctx.Err() != nil {
+				return graph, warnings, timeoutError(ctx)
+			}
+			// Partial Fisher-Yates shuffle, the first i pairs are the ones chosen so far
+			j := i + r.Intn(len(pairs)-i)
+			pairs[i], pairs[j] = pairs[j], pairs[i]
+			graph.Connect(pairs[i])
+		}
 	} else {
-		// A random connection can try to recreate an existing edge, which won't change the graph
-		// Iterate until a large threshold to get as close to desired edgecount
-		for iterations := 0; edgeCount < maxEdges && iterations < 100000; {
+		// At least half of the pairs are unconnected, so a random pair is new with probability 1/2 or more
+		for tries := 0; len(graph.edges) < maxEdges; tries++ {
+			if tries%contextCheckInterval == 0 && ctx.Err() != nil {
+				return graph, warnings, timeoutError(ctx)
+			}
 			a := group[r.Intn(len(group))]
 			b := group[r.Intn(len(group))]
 			if !graph.edges.Check(Edge[T]{a, b}) {
 				graph.Connect(Edge[T]{a, b})
-				edgeCount++
-			} else {
-				iterations++
 			}
 		}
 	}
 
-	return graph
+	return graph, warnings, nil
 }
 
 // Depth first traversal of graph, track visited nodes and number their depth
@@ -264,6 +347,33 @@ func (g *Graph[T]) Colored(m int) (bool, int, EdgeSet[T]) {
 	return len(conflicts) == 0 && len(colors) <= m, len(colors), conflicts
 }
 
+// Check whether an external color assignment colors the graph with max m colors
+// Nodes missing from the assignment count as conflicts, and every color has to be in 0..m-1
+// Returns the amount of colors used and possible conflict edges
+func (g *Graph[T]) ColoredWith(a ColorAssignment[T], m int) (bool, int, EdgeSet[T]) {
+	colors := make(map[int]bool)
+	conflicts := make(EdgeSet[T])
+	invalid := 0
+
+	for n := range g.nodes {
+		c, ok := a[n]
+		if ok {
+			colors[c] = true
+		}
+		if !ok || c < 0 || c >= m {
+			invalid++
+		}
+	}
+	for e := range g.edges {
+		ca, okA := a[e.a]
+		cb, okB := a[e.b]
+		if !okA || !okB || ca == cb {
+			conflicts[e] = true
+		}
+	}
+	return len(conflicts) == 0 && len(colors) <= m && invalid == 0, len(colors), conflicts
+}
+
 // Save edges in this json format
 type JsonEdge[T comparable] struct {
 	A    int
@@ -272,9 +382,8 @@ type JsonEdge[T comparable] struct {
 	BVal T
 }
 
-// Save a graph to a json file
-func (g *Graph[T]) SaveJson(path string) error {
-
+// Edges of the graph with nodes numbered from 1, as written by SaveJson
+func (g *Graph[T]) JsonEdges() []JsonEdge[T] {
 	nodeIds := make(map[*Node[T]]int)
 	jsonEdges := make([]JsonEdge[T], len(g.edges))
 
@@ -296,12 +405,19 @@ func (g *Graph[T]) SaveJson(path string) error {
 		jsonEdges[i] = JsonEdge[T]{nodeIds[k.a], nodeIds[k.b], k.a.value, k.b.value}
 		i++
 	}
+	return jsonEdges
+}
+
+// Save a graph to a json file
+func (g *Graph[T]) SaveJson(path string) error {
+	jsonEdges := g.JsonEdges()
 
 	file, err := os.Create(path)
 	if err != nil {
 		fmt.Println("Failed to open", path)
 		return err
 	}
+	defer file.Close()
 
 	data, err := json.Marshal(jsonEdges)
 	if err != nil {
@@ -320,23 +436,29 @@ func (g *Graph[T]) SaveJson(path string) error {
 
 // Load a graph from a json file
 func LoadGraphJson[T comparable](path string) (*Graph[T], error) {
-
-	nodes := make(map[int]*Node[T])
-	jsonEdges := new([]JsonEdge[T])
 	bytes, err := os.ReadFile(path)
-	graph := EmptyGraph[T]()
-
 	if err != nil {
 		fmt.Printf("Failed to read file %s\n", path)
 		return nil, err
 	}
+	return ParseGraphJson[T](bytes)
+}
 
-	if err := json.Unmarshal(bytes, jsonEdges); err != nil {
+// Decode a graph from the json edge list written by SaveJson
+func ParseGraphJson[T comparable](data []byte) (*Graph[T], error) {
+	nodes := make(map[int]*Node[T])
+	jsonEdges := new([]JsonEdge[T])
+	graph := EmptyGraph[T]()
+
+	if err := json.Unmarshal(data, jsonEdges); err != nil {
 		fmt.Println("Failed to decode json")
 		return nil, err
 	}
 
 	for _, v := range *jsonEdges {
+		if v.A == v.B {
+			return nil, fmt.Errorf("Edge connects node %d to itself", v.A)
+		}
 		if _, ok := nodes[v.A]; !ok {
 			nodes[v.A] = graph.AddNodes(1, v.AVal)[0]
 		}
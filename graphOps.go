@@ -0,0 +1,221 @@
+// Graph operations
+// Operations other than node removal build a fresh graph with new nodes,
+// and return a mapping from the original nodes to the new ones (one per operand for union and join)
+
+package main
+
+import "sort"
+
+// Remove a node and its edges from the graph
+func (g *Graph[T]) RemoveNode(n *Node[T]) {
+	for e := range n.neighbours {
+		g.Disconnect(e)
+	}
+	delete(g.nodes, n)
+
+	// Pick a new root from the remaining nodes
+	if g.root == n {
+		g.root = nil
+		if nodes := g.Nodes(); len(nodes) > 0 {
+			g.root = nodes[0]
+		}
+	}
+}
+
+// Copy the given nodes into graph g, keeping their order and values
+func (g *Graph[T]) copyNodes(nodes []*Node[T], mapping map[*Node[T]]*Node[T]) {
+	for _, n := range nodes {
+		mapping[n] = g.AddNodes(1, n.value)[0]
+	}
+}
+
+// Copy the edges between mapped nodes into graph g
+func (g *Graph[T]) copyEdges(edges EdgeSet[T], mapping map[*Node[T]]*Node[T]) {
+	for e := range edges {
+		a, okA := mapping[e.a]
+		b, okB := mapping[e.b]
+		if okA && okB {
+			g.Connect(Edge[T]{a, b})
+		}
+	}
+}
+
+// Deep copy the graph, the copy shares no nodes or edges with the original
+func (g *Graph[T]) Clone() (*Graph[T], map[*Node[T]]*Node[T]) {
+	return g.InducedSubgraph(g.nodes)
+}
+
+// Node values saved from a graph, e.g. a coloring
+type Snapshot[T comparable] map[*Node[T]]T
+
+// Save the current node values
+func (g *Graph[T]) Snapshot() Snapshot[T] {
+	s := make(Snapshot[T], len(g.nodes))
+	for n := range g.nodes {
+		s[n] = n.value
+	}
+	return s
+}
+
+// Restore node values from a snapshot, nodes missing from the snapshot are left unchanged
+func (g *Graph[T]) Restore(s Snapshot[T]) {
+	for n := range g.nodes {
+		if v, ok := s[n]; ok {
+			n.value = v
+		}
+	}
+}
+
+// Build the subgraph containing the given nodes and all edges between them
+func (g *Graph[T]) InducedSubgraph(nodes NodeSet[T]) (*Graph[T], map[*Node[T]]*Node[T]) {
+	sub := EmptyGraph[T]()
+	mapping := make(map[*Node[T]]*Node[T], len(nodes))
+
+	ordered := make([]*Node[T], 0, len(nodes))
+	for _, n := range g.Nodes() {
+		if nodes[n] {
+			ordered = append(ordered, n)
+		}
+	}
+	sub.copyNodes(ordered, mapping)
+	sub.copyEdges(g.edges, mapping)
+
+	// Keep the original root if it was included
+	if r, ok := mapping[g.root]; ok {
+		sub.root = r
+	}
+	return sub, mapping
+}
+
+// Split the nodes into connected components, ordered by their first node
+func (g *Graph[T]) Components() []NodeSet[T] {
+	visited := make(NodeSet[T])
+	components := make([]NodeSet[T], 0)
+	for _, n := range g.Nodes() {
+		if visited[n] {
+			continue
+		}
+		component := make(NodeSet[T])
+		n.Walk(visited, 0, func(m *Node[T], _ int, _ NodeSet[T]) {
+			component[m] = true
+		})
+		components = append(components, component)
+	}
+	return components
+}
+
+// Build the complement graph, where nodes are adjacent exactly when they are not adjacent in g
+func (g *Graph[T]) Complement() (*Graph[T], map[*Node[T]]*Node[T]) {
+	comp := EmptyGraph[T]()
+	mapping := make(map[*Node[T]]*Node[T], len(g.nodes))
+
+	nodes := g.Nodes()
+	comp.copyNodes(nodes, mapping)
+	for i, a := range nodes {
+		for _, b := range nodes[i+1:] {
+			if !a.neighbours.Check(Edge[T]{a, b}) {
+				comp.Connect(Edge[T]{mapping[a], mapping[b]})
+			}
+		}
+	}
+
+	comp.root = mapping[g.root]
+	return comp, mapping
+}
+
+// Build the disjoint union of graphs g and h
+// Each graph gets its own mapping, so g and h may be the same graph or share nodes
+func (g *Graph[T]) DisjointUnion(h *Graph[T]) (*Graph[T], map[*Node[T]]*Node[T], map[*Node[T]]*Node[T]) {
+	union := EmptyGraph[T]()
+	gMapping := make(map[*Node[T]]*Node[T], len(g.nodes))
+	hMapping := make(map[*Node[T]]*Node[T], len(h.nodes))
+
+	union.copyNodes(g.Nodes(), gMapping)
+	union.copyNodes(h.Nodes(), hMapping)
+	union.copyEdges(g.edges, gMapping)
+	union.copyEdges(h.edges, hMapping)
+
+	if r, ok := gMapping[g.root]; ok {
+		union.root = r
+	} else if r, ok := hMapping[h.root]; ok {
+		union.root = r
+	}
+	return union, gMapping, hMapping
+}
+
+// Build the join of graphs g and h, their disjoint union with every node of g connected to every node of h
+func (g *Graph[T]) Join(h *Graph[T]) (*Graph[T], map[*Node[T]]*Node[T], map[*Node[T]]*Node[T]) {
+	join, gMapping, hMapping := g.DisjointUnion(h)
+	for a := range g.nodes {
+		for b := range h.nodes {
+			join.Connect(Edge[T]{gMapping[a], hMapping[b]})
+		}
+	}
+	return join, gMapping, hMapping
+}
+
+// Build the line graph, which has a node for every edge of g with the given value
+// Two nodes are adjacent when their edges share an endpoint
+// Returns the mapping from the new nodes to the edges of g
+func (g *Graph[T]) LineGraph(value T) (*Graph[T], map[*Node[T]]Edge[T]) {
+	line := EmptyGraph[T]()
+	edgeNodes := make(map[Edge[T]]*Node[T], len(g.edges))
+	mapping := make(map[*Node[T]]Edge[T], len(g.edges))
+
+	// Number the edges in node order so the new graph doesn't depend on map order
+	for _, n := range g.Nodes() {
+		for _, e := range n.sortedEdges() {
+			if _, ok := edgeNodes[e]; !ok {
+				node := line.AddNodes(1, value)[0]
+				edgeNodes[e] = node
+				mapping[node] = e
+			}
+		}
+	}
+
+	// Edges sharing an endpoint form a clique in the line graph
+	for n := range g.nodes {
+		incident := n.sortedEdges()
+		for i, e := range incident {
+			for _, f := range incident[i+1:] {
+				line.Connect(Edge[T]{edgeNodes[e], edgeNodes[f]})
+			}
+		}
+	}
+	return line, mapping
+}
+
+// Build the graph where the endpoints of edge e are merged into a single node with the value of e.a
+// Both endpoints map to the merged node, and parallel edges are merged
+func (g *Graph[T]) Contract(e Edge[T]) (*Graph[T], map[*Node[T]]*Node[T]) {
+	contracted := EmptyGraph[T]()
+	mapping := make(map[*Node[T]]*Node[T], len(g.nodes))
+
+	for _, n := range g.Nodes() {
+		if n == e.b {
+			continue
+		}
+		mapping[n] = contracted.AddNodes(1, n.value)[0]
+	}
+	mapping[e.b] = mapping[e.a]
+
+	for k := range g.edges {
+		a, b := mapping[k.a], mapping[k.b]
+		if a != b {
+			contracted.Connect(Edge[T]{a, b})
+		}
+	}
+
+	contracted.root = mapping[g.root]
+	return contracted, mapping
+}
+
+// List the edges of a node ordered by the neighbouring node
+func (n *Node[T]) sortedEdges() []Edge[T] {
+	edges := make([]Edge[T], 0, len(n.neighbours))
+	for e := range n.neighbours {
+		edges = append(edges, e)
+	}
+	sort.Slice(edges, func(i, j int) bool { return edges[i].Other(n).id < edges[j].Other(n).id })
+	return edges
+}
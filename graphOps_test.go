@@ -0,0 +1,258 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// Random connected graph with a few extra edges
+func randomOpsGraph(r *rand.Rand, seed int64) *Graph[int] {
+	N := 2 + r.Intn(12)
+	g, _ := RandomGraph(N, 0, N-1+r.Intn(2*N), seed)
+	return g
+}
+
+// Check whether nodes a and b are distinct and connected by an edge
+func adjacent[T comparable](a, b *Node[T]) bool {
+	return a != b && a.neighbours.Check(Edge[T]{a, b})
+}
+
+// Check that mapped nodes of g are adjacent in h exactly when they are adjacent in g
+func checkMappedEdges[T comparable](t *testing.T, g *Graph[T], mapping map[*Node[T]]*Node[T]) {
+	t.Helper()
+	for a := range g.nodes {
+		for b := range g.nodes {
+			if a != b && adjacent(a, b) != adjacent(mapping[a], mapping[b]) {
+				t.Fatalf("Nodes %d and %d changed adjacency in the copy", a.id, b.id)
+			}
+		}
+	}
+}
+
+// Check that a mapping sends the nodes of g to distinct nodes
+func checkDistinct[T comparable](t *testing.T, mappings ...map[*Node[T]]*Node[T]) {
+	t.Helper()
+	seen := make(NodeSet[T])
+	for _, mapping := range mappings {
+		for n, m := range mapping {
+			if seen[m] {
+				t.Fatalf("Node %d is mapped onto a node that is already used", n.id)
+			}
+			seen[m] = true
+		}
+	}
+}
+
+func TestDisjointUnionAndJoin(t *testing.T) {
+	r := rand.New(rand.NewSource(20))
+	for seed := int64(1); seed <= 200; seed++ {
+		g := randomOpsGraph(r, seed)
+		h := randomOpsGraph(r, -seed)
+		if seed%4 == 0 {
+			// Union and join of a graph with itself
+			h = g
+		}
+		G, H := len(g.nodes), len(h.nodes)
+
+		union, gMapping, hMapping := g.DisjointUnion(h)
+		checkConsistent(t, union)
+		checkDistinct(t, gMapping, hMapping)
+		if len(union.nodes) != G+H || len(union.edges) != len(g.edges)+len(h.edges) {
+			t.Fatalf("Seed %d: union has %d nodes and %d edges, expected %d and %d", seed, len(union.nodes), len(union.edges), G+H, len(g.edges)+len(h.edges))
+		}
+		checkMappedEdges(t, g, gMapping)
+		checkMappedEdges(t, h, hMapping)
+		if connected, _ := union.Connected(); connected {
+			t.Fatalf("Seed %d: union of two graphs is connected", seed)
+		}
+
+		join, gMapping, hMapping := g.Join(h)
+		checkConsistent(t, join)
+		checkDistinct(t, gMapping, hMapping)
+		if len(join.nodes) != G+H || len(join.edges) != len(g.edges)+len(h.edges)+G*H {
+			t.Fatalf("Seed %d: join has %d nodes and %d edges, expected %d and %d", seed, len(join.nodes), len(join.edges), G+H, len(g.edges)+len(h.edges)+G*H)
+		}
+		checkMappedEdges(t, g, gMapping)
+		checkMappedEdges(t, h, hMapping)
+		for a := range g.nodes {
+			for b := range h.nodes {
+				if !adjacent(gMapping[a], hMapping[b]) {
+					t.Fatalf("Seed %d: join doesn't connect node %d of g to node %d of h", seed, a.id, b.id)
+				}
+			}
+		}
+	}
+}
+
+func TestDisjointUnionWithItself(t *testing.T) {
+	g, _ := RandomGraph(10, 0, 15, 1)
+	union, _, _ := g.DisjointUnion(g)
+	if len(union.nodes) != 20 || len(union.edges) != 30 {
+		t.Fatalf("Union with itself has %d nodes and %d edges, expected 20 and 30", len(union.nodes), len(union.edges))
+	}
+	join, _, _ := g.Join(g)
+	if len(join.nodes) != 20 || len(join.edges) != 130 {
+		t.Fatalf("Join with itself has %d nodes and %d edges, expected 20 and 130", len(join.nodes), len(join.edges))
+	}
+}
+
+func TestRemoveNode(t *testing.T) {
+	r := rand.New(rand.NewSource(21))
+	for seed := int64(1); seed <= 200; seed++ {
+		g := randomOpsGraph(r, seed)
+		nodes := g.Nodes()
+		n := nodes[r.Intn(len(nodes))]
+		if seed%5 == 0 {
+			n = g.root
+		}
+		N, E, degree := len(g.nodes), len(g.edges), len(n.neighbours)
+
+		g.RemoveNode(n)
+		checkConsistent(t, g)
+		if len(g.nodes) != N-1 || len(g.edges) != E-degree {
+			t.Fatalf("Seed %d: %d nodes and %d edges left, expected %d and %d", seed, len(g.nodes), len(g.edges), N-1, E-degree)
+		}
+		for e := range g.edges {
+			if e.a == n || e.b == n {
+				t.Fatalf("Seed %d: edge %v still uses the removed node", seed, e)
+			}
+		}
+		if g.root == n || (g.root == nil) != (N == 1) || (g.root != nil && !g.nodes[g.root]) {
+			t.Fatalf("Seed %d: root %v is not one of the remaining nodes", seed, g.root)
+		}
+	}
+}
+
+func TestInducedSubgraph(t *testing.T) {
+	r := rand.New(rand.NewSource(22))
+	for seed := int64(1); seed <= 200; seed++ {
+		g := randomOpsGraph(r, seed)
+		subset := make(NodeSet[int])
+		for n := range g.nodes {
+			if r.Intn(2) == 0 {
+				subset[n] = true
+			}
+		}
+
+		sub, mapping := g.InducedSubgraph(subset)
+		checkConsistent(t, sub)
+		checkDistinct(t, mapping)
+		if len(sub.nodes) != len(subset) || len(mapping) != len(subset) {
+			t.Fatalf("Seed %d: subgraph has %d nodes, expected %d", seed, len(sub.nodes), len(subset))
+		}
+		edges := 0
+		for e := range g.edges {
+			if subset[e.a] && subset[e.b] {
+				edges++
+			}
+		}
+		if len(sub.edges) != edges {
+			t.Fatalf("Seed %d: subgraph has %d edges, expected %d", seed, len(sub.edges), edges)
+		}
+		for a := range subset {
+			for b := range subset {
+				if adjacent(a, b) != adjacent(mapping[a], mapping[b]) {
+					t.Fatalf("Seed %d: subgraph changes the edge between %d and %d", seed, a.id, b.id)
+				}
+			}
+		}
+	}
+}
+
+func TestComplement(t *testing.T) {
+	r := rand.New(rand.NewSource(23))
+	for seed := int64(1); seed <= 200; seed++ {
+		g := randomOpsGraph(r, seed)
+		N := len(g.nodes)
+
+		comp, mapping := g.Complement()
+		checkConsistent(t, comp)
+		checkDistinct(t, mapping)
+		if len(comp.nodes) != N || len(comp.edges) != N*(N-1)/2-len(g.edges) {
+			t.Fatalf("Seed %d: complement has %d nodes and %d edges, expected %d and %d", seed, len(comp.nodes), len(comp.edges), N, N*(N-1)/2-len(g.edges))
+		}
+		for a := range g.nodes {
+			for b := range g.nodes {
+				if a != b && adjacent(a, b) == adjacent(mapping[a], mapping[b]) {
+					t.Fatalf("Seed %d: nodes %d and %d have the same adjacency in the complement", seed, a.id, b.id)
+				}
+			}
+		}
+
+		// Taking the complement twice gives back the original graph
+		again, _ := comp.Complement()
+		if again.CanonicalForm() != g.CanonicalForm() {
+			t.Fatalf("Seed %d: complement of the complement differs from the graph", seed)
+		}
+	}
+}
+
+func TestLineGraph(t *testing.T) {
+	r := rand.New(rand.NewSource(24))
+	for seed := int64(1); seed <= 200; seed++ {
+		g := randomOpsGraph(r, seed)
+
+		line, mapping := g.LineGraph(0)
+		checkConsistent(t, line)
+		if len(line.nodes) != len(g.edges) || len(mapping) != len(g.edges) {
+			t.Fatalf("Seed %d: line graph has %d nodes, expected %d", seed, len(line.nodes), len(g.edges))
+		}
+		edges := 0
+		for n := range g.nodes {
+			edges += len(n.neighbours) * (len(n.neighbours) - 1) / 2
+		}
+		if len(line.edges) != edges {
+			t.Fatalf("Seed %d: line graph has %d edges, expected %d", seed, len(line.edges), edges)
+		}
+		for a, e := range mapping {
+			for b, f := range mapping {
+				shared := e.a == f.a || e.a == f.b || e.b == f.a || e.b == f.b
+				if a != b && adjacent(a, b) != shared {
+					t.Fatalf("Seed %d: edges %v and %v share an endpoint %v, adjacent %v", seed, e, f, shared, adjacent(a, b))
+				}
+			}
+		}
+	}
+}
+
+func TestContract(t *testing.T) {
+	r := rand.New(rand.NewSource(25))
+	for seed := int64(1); seed <= 200; seed++ {
+		g := randomOpsGraph(r, seed)
+		edges := make([]Edge[int], 0, len(g.edges))
+		for _, n := range g.Nodes() {
+			for _, e := range n.sortedEdges() {
+				if e.Other(n).id > n.id {
+					edges = append(edges, e)
+				}
+			}
+		}
+		e := edges[r.Intn(len(edges))]
+
+		// Neighbours shared by both endpoints merge their two edges into one
+		common := 0
+		for n := range g.nodes {
+			if adjacent(n, e.a) && adjacent(n, e.b) {
+				common++
+			}
+		}
+
+		contracted, mapping := g.Contract(e)
+		checkConsistent(t, contracted)
+		if len(contracted.nodes) != len(g.nodes)-1 || len(contracted.edges) != len(g.edges)-1-common {
+			t.Fatalf("Seed %d: contraction has %d nodes and %d edges, expected %d and %d", seed, len(contracted.nodes), len(contracted.edges), len(g.nodes)-1, len(g.edges)-1-common)
+		}
+		merged := mapping[e.a]
+		if mapping[e.b] != merged || merged.value != e.a.value {
+			t.Fatalf("Seed %d: endpoints of %v are not merged", seed, e)
+		}
+		for n := range g.nodes {
+			if n == e.a || n == e.b {
+				continue
+			}
+			if adjacent(mapping[n], merged) != (adjacent(n, e.a) || adjacent(n, e.b)) {
+				t.Fatalf("Seed %d: node %d lost or gained an edge to the merged node", seed, n.id)
+			}
+		}
+	}
+}
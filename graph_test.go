@@ -0,0 +1,354 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"sort"
+	"testing"
+	"testing/quick"
+)
+
+// Unordered pair of node indexes, the model of an undirected edge
+type pair struct{ a, b int }
+
+func newPair(a, b int) pair {
+	if b < a {
+		a, b = b, a
+	}
+	return pair{a, b}
+}
+
+// Edges of the graph as unordered pairs of node ids
+func edgePairs[T comparable](g *Graph[T]) map[pair]bool {
+	pairs := make(map[pair]bool)
+	for e := range g.edges {
+		pairs[newPair(e.a.id, e.b.id)] = true
+	}
+	return pairs
+}
+
+// Edges of the graph as unordered pairs of node ids in sorted order
+func sortedPairs[T comparable](g *Graph[T]) []pair {
+	pairs := make([]pair, 0, len(g.edges))
+	for p := range edgePairs(g) {
+		pairs = append(pairs, p)
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairs[i].a < pairs[j].a || (pairs[i].a == pairs[j].a && pairs[i].b < pairs[j].b)
+	})
+	return pairs
+}
+
+// Check that the edge set and the neighbour sets describe the same graph
+func checkConsistent[T comparable](t *testing.T, g *Graph[T]) {
+	t.Helper()
+	degrees := 0
+	for n := range g.nodes {
+		for e := range n.neighbours {
+			if e.a != n && e.b != n {
+				t.Fatalf("Node %d has edge %v it is not part of", n.id, e)
+			}
+			if !g.edges.Check(e) {
+				t.Fatalf("Neighbour edge %v of node %d is missing from the graph", e, n.id)
+			}
+			if !e.Other(n).neighbours.Check(e) {
+				t.Fatalf("Edge %v is only stored on one side", e)
+			}
+			degrees++
+		}
+	}
+	if degrees != 2*len(g.edges) {
+		t.Fatalf("Degrees sum to %d for %d edges", degrees, len(g.edges))
+	}
+}
+
+func TestEdgeSetCheckIsSymmetric(t *testing.T) {
+	nodes := NewGraph(8, 0).Nodes()
+	property := func(i, j uint8) bool {
+		a, b := nodes[int(i)%len(nodes)], nodes[int(j)%len(nodes)]
+		s := make(EdgeSet[int])
+		s.Add(Edge[int]{a, b})
+		return s.Check(Edge[int]{a, b}) && s.Check(Edge[int]{b, a})
+	}
+	if err := quick.Check(property, &quick.Config{MaxCount: 5000}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestEdgeSetAddMatchesModel(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for round := 0; round < 1000; round++ {
+		nodes := NewGraph(2+r.Intn(10), 0).Nodes()
+		s := make(EdgeSet[int])
+		model := make(map[pair]bool)
+
+		for step := 0; step < 30; step++ {
+			a, b := nodes[r.Intn(len(nodes))], nodes[r.Intn(len(nodes))]
+			if a == b {
+				// Self loops count as present so they are never added
+				if !s.Check(Edge[int]{a, b}) {
+					t.Fatal("Self loop should always be present")
+				}
+				continue
+			}
+			s.Add(Edge[int]{a, b})
+			model[newPair(a.id, b.id)] = true
+		}
+
+		if len(s) != len(model) {
+			t.Fatalf("Edge set has %d edges, model has %d", len(s), len(model))
+		}
+		for _, a := range nodes {
+			for _, b := range nodes {
+				if a != b && s.Check(Edge[int]{a, b}) != model[newPair(a.id, b.id)] {
+					t.Fatalf("Check(%d, %d) disagrees with the model", a.id, b.id)
+				}
+			}
+		}
+	}
+}
+
+func TestConnectDisconnectMatchesModel(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	for round := 0; round < 1000; round++ {
+		g := NewGraph(2+r.Intn(12), 0)
+		nodes := g.Nodes()
+		model := make(map[pair]bool)
+
+		for step := 0; step < 50; step++ {
+			a, b := nodes[r.Intn(len(nodes))], nodes[r.Intn(len(nodes))]
+			if a == b {
+				continue
+			}
+			// Either orientation refers to the same edge
+			e := Edge[int]{a, b}
+			if r.Intn(2) == 0 {
+				e = Edge[int]{b, a}
+			}
+			if r.Intn(3) == 0 {
+				g.Disconnect(e)
+				delete(model, newPair(a.id, b.id))
+			} else {
+				g.Connect(e)
+				model[newPair(a.id, b.id)] = true
+			}
+		}
+
+		checkConsistent(t, g)
+		got := edgePairs(g)
+		if len(got) != len(model) || len(g.edges) != len(model) {
+			t.Fatalf("Graph has %d edges, model has %d", len(g.edges), len(model))
+		}
+		for p := range model {
+			if !got[p] {
+				t.Fatalf("Edge %v is missing", p)
+			}
+		}
+	}
+}
+
+func TestRandomGraphConnected(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	for seed := int64(1); seed <= 2000; seed++ {
+		N := 2 + r.Intn(40)
+		maxEdges := r.Intn(3 * N)
+		g, _ := RandomGraph(N, 0, maxEdges, seed)
+
+		if len(g.nodes) != N {
+			t.Fatalf("Seed %d: %d nodes, expected %d", seed, len(g.nodes), N)
+		}
+		if connected, count := g.Connected(); !connected {
+			t.Fatalf("Seed %d: only %d of %d nodes reachable", seed, count, N)
+		}
+		checkConsistent(t, g)
+	}
+}
+
+func TestRandomGraphEdgeCount(t *testing.T) {
+	r := rand.New(rand.NewSource(4))
+	for seed := int64(1); seed <= 2000; seed++ {
+		N := 2 + r.Intn(40)
+		complete := N * (N - 1) / 2
+		maxEdges := r.Intn(complete + N)
+		g, warnings := RandomGraph(N, 0, maxEdges, seed)
+
+		// Exactly the requested edges, clamped between a spanning tree and the complete graph
+		expected := min(max(maxEdges, N-1), complete)
+		if len(g.edges) != expected {
+			t.Fatalf("Seed %d: %d edges on %d nodes, asked for %d", seed, len(g.edges), N, maxEdges)
+		}
+		if (expected != maxEdges) != (len(warnings) > 0) {
+			t.Fatalf("Seed %d: asked for %d edges and got %d with warnings %q", seed, maxEdges, expected, warnings)
+		}
+		for e := range g.edges {
+			if e.a == e.b {
+				t.Fatalf("Seed %d: self loop", seed)
+			}
+		}
+		checkConsistent(t, g)
+	}
+}
+
+func TestRandomGraphDense(t *testing.T) {
+	for seed := int64(1); seed <= 50; seed++ {
+		// Complete and nearly complete graphs are built from the list of unconnected pairs
+		for _, missing := range []int{0, 1, 5} {
+			N := 60
+			maxEdges := N*(N-1)/2 - missing
+			g, warnings := RandomGraph(N, 0, maxEdges, seed)
+			if len(g.edges) != maxEdges || len(warnings) != 0 {
+				t.Fatalf("Seed %d: %d edges with warnings %q, asked for %d", seed, len(g.edges), warnings, maxEdges)
+			}
+			if connected, _ := g.Connected(); !connected {
+				t.Fatalf("Seed %d: dense graph is not connected", seed)
+			}
+		}
+	}
+}
+
+func TestRandomGraphTiny(t *testing.T) {
+	for N := 0; N <= 2; N++ {
+		g, _ := RandomGraph(N, 0, 5, 1)
+		if len(g.nodes) != N || len(g.edges) != max(N-1, 0) {
+			t.Errorf("%d nodes gave %d nodes and %d edges", N, len(g.nodes), len(g.edges))
+		}
+	}
+}
+
+func TestRandomGraphDeterministic(t *testing.T) {
+	for seed := int64(1); seed <= 200; seed++ {
+		a, _ := RandomGraph(30, 0, 60, seed)
+		b, _ := RandomGraph(30, 0, 60, seed)
+		if a.CanonicalForm() != b.CanonicalForm() {
+			t.Fatalf("Seed %d generated two different graphs", seed)
+		}
+		if fmt.Sprint(edgePairs(a)) != fmt.Sprint(edgePairs(b)) {
+			t.Fatalf("Seed %d generated the same graph with different node ids", seed)
+		}
+	}
+}
+
+// Graph generated by RandomGraph(12, 0, 20, 42)
+const pinnedEdges = "[{0 1} {0 8} {1 2} {1 6} {2 3} {2 4} {2 5} {2 9} {3 4} {3 5} {3 10} {3 11} {4 6} {4 8} {5 11} {6 7} {6 11} {8 9} {8 10} {8 11}]"
+const pinnedHash uint64 = 0x3eb168ba127eb8e7
+
+// The graph generated for a seed must not change between runs or Go versions
+func TestRandomGraphPinned(t *testing.T) {
+	g, _ := RandomGraph(12, 0, 20, 42)
+	if got := fmt.Sprint(sortedPairs(g)); got != pinnedEdges {
+		t.Errorf("Seed 42 generated edges\n%s\nexpected\n%s", got, pinnedEdges)
+	}
+	if got := g.CanonicalHash(); got != pinnedHash {
+		t.Errorf("Seed 42 generated graph with hash %#x, expected %#x", got, pinnedHash)
+	}
+}
+
+func TestJsonRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(5))
+	dir := t.TempDir()
+	for seed := int64(1); seed <= 300; seed++ {
+		N := 2 + r.Intn(30)
+		g, _ := RandomGraph(N, "", r.Intn(3*N), seed)
+		for _, n := range g.Nodes() {
+			n.value = fmt.Sprintf("v%d", r.Intn(4))
+		}
+
+		path := filepath.Join(dir, fmt.Sprintf("graph%d.json", seed))
+		if err := g.SaveJson(path); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := LoadGraphJson[string](path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		checkConsistent(t, loaded)
+		if loaded.CanonicalForm() != g.CanonicalForm() {
+			t.Fatalf("Seed %d: loaded graph differs\n%s\n%s", seed, g.CanonicalForm(), loaded.CanonicalForm())
+		}
+		if ok, mapping := Isomorphic(g, loaded); !ok || len(mapping) != N {
+			t.Fatalf("Seed %d: loaded graph is not isomorphic", seed)
+		}
+	}
+}
+
+func TestJsonRoundTripInts(t *testing.T) {
+	g, _ := RandomGraph(20, 0, 40, 6)
+	for i, n := range g.Nodes() {
+		n.value = i * i
+	}
+	path := filepath.Join(t.TempDir(), "graph.json")
+	if err := g.SaveJson(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadGraphJson[int](path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.CanonicalForm() != g.CanonicalForm() {
+		t.Fatal("Loaded graph differs")
+	}
+}
+
+func TestLoadGraphJsonErrors(t *testing.T) {
+	if _, err := LoadGraphJson[string](filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
+
+func TestColored(t *testing.T) {
+	r := rand.New(rand.NewSource(7))
+	for seed := int64(1); seed <= 1000; seed++ {
+		N := 2 + r.Intn(30)
+		g, _ := RandomGraph(N, "", r.Intn(3*N), seed)
+		assignment, used, _ := g.ColorGreedyBest()
+		assignment.Apply(ColorPalette(used))
+
+		if ok, count, conflicts := g.Colored(used); !ok || count != used || len(conflicts) != 0 {
+			t.Fatalf("Seed %d: proper %d-coloring reported as %v with %d colors and conflicts %v", seed, used, ok, count, conflicts)
+		}
+		if used > 1 {
+			if ok, _, _ := g.Colored(used - 1); ok {
+				t.Fatalf("Seed %d: %d colors accepted with a limit of %d", seed, used, used-1)
+			}
+		}
+
+		// Giving a node its neighbour's color creates a conflict on that edge
+		n := g.Nodes()[r.Intn(N)]
+		for e := range n.neighbours {
+			n.value = e.Other(n).value
+			ok, _, conflicts := g.Colored(used)
+			if ok || !conflicts[e] {
+				t.Fatalf("Seed %d: conflict on edge %v not reported", seed, e)
+			}
+			break
+		}
+	}
+}
+
+func TestColoredEmptyGraph(t *testing.T) {
+	if ok, count, _ := EmptyGraph[string]().Colored(0); !ok || count != 0 {
+		t.Error("Empty graph should count as colored with no colors")
+	}
+}
+
+func TestColoredWithColorRange(t *testing.T) {
+	g := NewGraph(3, 0)
+	n := g.Nodes()
+	g.Connect(Edge[int]{n[0], n[1]})
+	g.Connect(Edge[int]{n[1], n[2]})
+
+	a := ColorAssignment[int]{n[0]: 0, n[1]: 1, n[2]: 0}
+	if ok, count, _ := g.ColoredWith(a, 2); !ok || count != 2 {
+		t.Fatalf("Proper 2-coloring reported as %v with %d colors", ok, count)
+	}
+
+	// Two distinct colors, but not both of them in 0..m-1
+	for _, c := range []int{-1, 2, 5} {
+		a[n[1]] = c
+		if ok, _, _ := g.ColoredWith(a, 2); ok {
+			t.Errorf("Color %d accepted with a limit of 2 colors", c)
+		}
+	}
+}
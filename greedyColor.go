@@ -0,0 +1,186 @@
+// Greedy graph coloring
+
+// Nodes are colored one at a time in a chosen order, each with the smallest color
+// not used by its neighbours. The result is a fast upper bound for the number of colors needed
+
+package main
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sort"
+)
+
+// Order in which the greedy colorer visits the nodes
+type VertexOrdering int
+
+const (
+	NaturalOrder    VertexOrdering = iota // Order the nodes were added
+	LargestFirst                          // Decreasing degree (Welsh-Powell)
+	SmallestLast                          // Reverse of repeatedly removing the smallest degree node
+	IncidenceDegree                       // Most neighbours already ordered first
+	RandomOrder                           // Seeded random permutation
+)
+
+var orderingNames = []string{"natural", "largest-first", "smallest-last", "incidence-degree", "random"}
+
+// Format an ordering to string
+func (o VertexOrdering) String() string {
+	if o < 0 || int(o) >= len(orderingNames) {
+		return "unknown"
+	}
+	return orderingNames[o]
+}
+
+// Read an ordering from its name
+func ParseVertexOrdering(s string) (VertexOrdering, error) {
+	for i, name := range orderingNames {
+		if name == s {
+			return VertexOrdering(i), nil
+		}
+	}
+	return 0, errors.New("Not a recognized ordering")
+}
+
+// Order the numbered nodes, the seed is only used for the random ordering
+func orderNodes(adjacency [][]int, ordering VertexOrdering, seed int64) []int {
+	N := len(adjacency)
+	order := make([]int, N)
+	for i := range order {
+		order[i] = i
+	}
+
+	switch ordering {
+	case LargestFirst:
+		sort.SliceStable(order, func(a, b int) bool {
+			return len(adjacency[order[a]]) > len(adjacency[order[b]])
+		})
+
+	case SmallestLast:
+		// Keep nodes in buckets by their remaining degree, stale entries are skipped
+		degree := make([]int, N)
+		buckets := make([][]int, N)
+		for i := range adjacency {
+			degree[i] = len(adjacency[i])
+			buckets[degree[i]] = append(buckets[degree[i]], i)
+		}
+		removed := make([]bool, N)
+		for k, d := N-1, 0; k >= 0; {
+			if len(buckets[d]) == 0 {
+				d++
+				continue
+			}
+			i := buckets[d][len(buckets[d])-1]
+			buckets[d] = buckets[d][:len(buckets[d])-1]
+			if removed[i] || degree[i] != d {
+				continue
+			}
+			removed[i] = true
+			order[k] = i
+			k--
+			for _, j := range adjacency[i] {
+				if !removed[j] {
+					degree[j]--
+					buckets[degree[j]] = append(buckets[degree[j]], j)
+					d = min(d, degree[j])
+				}
+			}
+		}
+
+	case IncidenceDegree:
+		// Keep nodes in buckets by the number of ordered neighbours, stale entries are skipped
+		incidence := make([]int, N)
+		buckets := make([][]int, N+1)
+		for i := N - 1; i >= 0; i-- {
+			buckets[0] = append(buckets[0], i)
+		}
+		ordered := make([]bool, N)
+		for k, d := 0, 0; k < N; {
+			if len(buckets[d]) == 0 {
+				d--
+				continue
+			}
+			i := buckets[d][len(buckets[d])-1]
+			buckets[d] = buckets[d][:len(buckets[d])-1]
+			if ordered[i] || incidence[i] != d {
+				continue
+			}
+			ordered[i] = true
+			order[k] = i
+			k++
+			for _, j := range adjacency[i] {
+				if !ordered[j] {
+					incidence[j]++
+					buckets[incidence[j]] = append(buckets[incidence[j]], j)
+					d = max(d, incidence[j])
+				}
+			}
+		}
+
+	case RandomOrder:
+		r := rand.New(rand.NewSource(seed))
+		r.Shuffle(N, func(a, b int) { order[a], order[b] = order[b], order[a] })
+	}
+
+	return order
+}
+
+// Color a graph G greedily, visiting the nodes in the given order
+// Returns the coloring and the number of colors used
+func (g *Graph[T]) ColorGreedy(ordering VertexOrdering, seed int64) (ColorAssignment[T], int) {
+	assignment, used, _ := g.ColorGreedyContext(context.Background(), ordering, seed)
+	return assignment, used
+}
+
+// Color a graph G greedily, stopping when the context is done
+// On timeout, the nodes colored so far are returned with ErrTimeout
+func (g *Graph[T]) ColorGreedyContext(ctx context.Context, ordering VertexOrdering, seed int64) (ColorAssignment[T], int, error) {
+	nodes, _, adjacency := g.adjacencyLists()
+	colors := make([]int, len(nodes))
+	for i := range colors {
+		colors[i] = -1
+	}
+
+	var err error
+	used := 0
+	for k, i := range orderNodes(adjacency, ordering, seed) {
+		if k%contextCheckInterval == 0 && ctx.Err() != nil {
+			err = timeoutError(ctx)
+			break
+		}
+		taken := make([]bool, len(adjacency[i])+1)
+		for _, j := range adjacency[i] {
+			if c := colors[j]; c >= 0 && c < len(taken) {
+				taken[c] = true
+			}
+		}
+		c := 0
+		for taken[c] {
+			c++
+		}
+		colors[i] = c
+		used = max(used, c+1)
+	}
+
+	assignment := make(ColorAssignment[T], len(nodes))
+	for i, n := range nodes {
+		if colors[i] >= 0 {
+			assignment[n] = colors[i]
+		}
+	}
+	return assignment, used, err
+}
+
+// Run the greedy colorer with every deterministic ordering and keep the one using the fewest colors
+func (g *Graph[T]) ColorGreedyBest() (ColorAssignment[T], int, VertexOrdering) {
+	var best ColorAssignment[T]
+	bestUsed, bestOrdering := -1, NaturalOrder
+	for _, o := range []VertexOrdering{NaturalOrder, LargestFirst, SmallestLast, IncidenceDegree} {
+		assignment, used := g.ColorGreedy(o, 0)
+		if bestUsed < 0 || used < bestUsed {
+			best, bestUsed, bestOrdering = assignment, used, o
+		}
+	}
+	return best, bestUsed, bestOrdering
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+var allOrderings = []VertexOrdering{NaturalOrder, LargestFirst, SmallestLast, IncidenceDegree, RandomOrder}
+
+func TestColorGreedyOrderings(t *testing.T) {
+	r := rand.New(rand.NewSource(70))
+	for seed := int64(1); seed <= 300; seed++ {
+		var g *Graph[int]
+		if seed%2 == 0 {
+			N := 1 + r.Intn(60)
+			g, _ = RandomGraph(N, 0, N+r.Intn(4*N), seed)
+		} else {
+			g = randomDenseGraph(r, 1+r.Intn(25), r.Float64())
+		}
+
+		// Every node sees at most D colors on its neighbours, so D+1 colors are enough
+		D := g.MaxDegree()
+		_, bestUsed, _ := g.ColorGreedyBest()
+		for _, o := range allOrderings {
+			a, used := g.ColorGreedy(o, seed)
+			if ok, count, conflicts := g.ColoredWith(a, D+1); !ok || count != used {
+				t.Fatalf("Seed %d: %s ordering reported %d colors, colored %v with %d and conflicts %v", seed, o, used, ok, count, conflicts)
+			}
+			if o != RandomOrder && used < bestUsed {
+				t.Fatalf("Seed %d: %s ordering used %d colors, best of the orderings %d", seed, o, used, bestUsed)
+			}
+		}
+	}
+}
+
+func TestColorGreedySmallestLastTree(t *testing.T) {
+	for seed := int64(1); seed <= 200; seed++ {
+		// N-1 edges only make the spanning tree
+		N := 2 + int(seed%50)
+		g, _ := RandomGraph(N, 0, N-1, seed)
+
+		// Every subgraph of a tree has a leaf, so smallest-last colors it with two colors
+		a, used := g.ColorGreedy(SmallestLast, seed)
+		if ok, _, conflicts := g.ColoredWith(a, 2); !ok || used != 2 {
+			t.Fatalf("Seed %d: tree colored %v with %d colors and conflicts %v", seed, ok, used, conflicts)
+		}
+	}
+}
+
+func TestParseVertexOrdering(t *testing.T) {
+	for _, o := range allOrderings {
+		parsed, err := ParseVertexOrdering(o.String())
+		if err != nil || parsed != o {
+			t.Errorf("Ordering %s parsed as %s with error %v", o, parsed, err)
+		}
+	}
+	for _, name := range []string{"", "largest", "Natural", "unknown"} {
+		if _, err := ParseVertexOrdering(name); err == nil {
+			t.Errorf("Expected an error for ordering %q", name)
+		}
+	}
+}
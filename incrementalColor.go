@@ -0,0 +1,159 @@
+// Incremental graph coloring
+
+// Keeps a coloring valid while edges are added and removed by repairing only the
+// neighbourhood of the changed edge. A conflicting endpoint first tries a free color,
+// then tries moving a single blocking neighbour out of the way, and only as a last resort
+// takes a new color. The number of colors stays below max(initial colors, D+1)
+// where D is the maximum degree
+
+package main
+
+import "errors"
+
+// Coloring that is repaired on every edge insertion and deletion
+type IncrementalColoring[T comparable] struct {
+	graph     *Graph[T]
+	colors    ColorAssignment[T]
+	classes   map[int]int // Number of nodes with each color
+	limit     int         // Colors that can be used without adding a new one
+	Recolored int         // Total number of recolored nodes over all updates
+}
+
+// Start maintaining a coloring of the graph, initially colored greedily
+func NewIncrementalColoring[T comparable](g *Graph[T]) *IncrementalColoring[T] {
+	colors, used, _ := g.ColorGreedyBest()
+	ic := &IncrementalColoring[T]{graph: g, colors: colors, classes: make(map[int]int), limit: used}
+	for _, c := range colors {
+		ic.classes[c]++
+	}
+	return ic
+}
+
+// Current coloring
+func (ic *IncrementalColoring[T]) Colors() ColorAssignment[T] {
+	return ic.colors
+}
+
+// Number of colors currently in use
+func (ic *IncrementalColoring[T]) ColorCount() int {
+	return len(ic.classes)
+}
+
+// Add a node with the given value, it gets the first color
+func (ic *IncrementalColoring[T]) AddNode(value T) *Node[T] {
+	n := ic.graph.AddNodes(1, value)[0]
+	ic.set(n, 0)
+	ic.limit = max(ic.limit, 1)
+	return n
+}
+
+// Change the color of a node and keep the class sizes up to date
+func (ic *IncrementalColoring[T]) set(n *Node[T], c int) {
+	if old, ok := ic.colors[n]; ok {
+		ic.classes[old]--
+		if ic.classes[old] == 0 {
+			delete(ic.classes, old)
+		}
+	}
+	ic.colors[n] = c
+	ic.classes[c]++
+}
+
+// Colors below the limit not used by the neighbours of n, ignoring node skip
+func (ic *IncrementalColoring[T]) freeColors(n *Node[T], skip *Node[T]) []int {
+	used := make(map[int]bool)
+	for e := range n.neighbours {
+		if m := e.Other(n); m != skip {
+			used[ic.colors[m]] = true
+		}
+	}
+	free := make([]int, 0)
+	for c := 0; c < ic.limit; c++ {
+		if !used[c] {
+			free = append(free, c)
+		}
+	}
+	return free
+}
+
+// Find a valid color for node n, returns the number of recolored nodes
+func (ic *IncrementalColoring[T]) repair(n *Node[T]) int {
+
+	// A free color fixes the conflict directly
+	if free := ic.freeColors(n, nil); len(free) > 0 {
+		ic.set(n, free[0])
+		return 1
+	}
+
+	// A color blocked by a single neighbour is usable if that neighbour can move
+	blockers := make(map[int][]*Node[T])
+	for e := range n.neighbours {
+		m := e.Other(n)
+		blockers[ic.colors[m]] = append(blockers[ic.colors[m]], m)
+	}
+	for c := 0; c < ic.limit; c++ {
+		if len(blockers[c]) != 1 {
+			continue
+		}
+		m := blockers[c][0]
+		for _, k := range ic.freeColors(m, n) {
+			if k != c {
+				ic.set(m, k)
+				ic.set(n, c)
+				return 2
+			}
+		}
+	}
+
+	// Take a new color, n has at most D neighbours so this stays below D+1
+	ic.set(n, ic.limit)
+	ic.limit++
+	return 1
+}
+
+// Add edge e to the graph and repair the coloring
+// Returns the number of recolored nodes
+func (ic *IncrementalColoring[T]) Connect(e Edge[T]) (int, error) {
+	if e.a == e.b {
+		return 0, errors.New("Cannot connect a node to itself")
+	}
+	for _, n := range []*Node[T]{e.a, e.b} {
+		if _, ok := ic.colors[n]; !ok {
+			return 0, errors.New("Node is not part of the colored graph")
+		}
+	}
+
+	ic.graph.Connect(e)
+	recolored := 0
+	if ic.colors[e.a] == ic.colors[e.b] {
+		// Repair the endpoint with fewer neighbours to disturb
+		n := e.a
+		if len(e.b.neighbours) < len(e.a.neighbours) {
+			n = e.b
+		}
+		recolored = ic.repair(n)
+	}
+	ic.Recolored += recolored
+	return recolored, nil
+}
+
+// Remove edge e from the graph, and move its endpoints to smaller colors if possible
+// so that the highest colors empty out
+// Returns the number of recolored nodes
+func (ic *IncrementalColoring[T]) Disconnect(e Edge[T]) int {
+	ic.graph.Disconnect(e)
+	recolored := 0
+	for _, n := range []*Node[T]{e.a, e.b} {
+		if free := ic.freeColors(n, nil); len(free) > 0 && free[0] < ic.colors[n] {
+			ic.set(n, free[0])
+			recolored++
+		}
+	}
+
+	// Lower the limit when the top colors are no longer used
+	for ic.limit > 1 && ic.classes[ic.limit-1] == 0 {
+		ic.limit--
+	}
+	ic.Recolored += recolored
+	return recolored
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestIncrementalColoringRandomUpdates(t *testing.T) {
+	r := rand.New(rand.NewSource(15))
+	for round := 0; round < 200; round++ {
+		N := 2 + r.Intn(25)
+		g, _ := RandomGraph(N, 0, N-1+r.Intn(2*N), int64(round+1))
+		ic := NewIncrementalColoring(g)
+		initial := ic.ColorCount()
+		nodes := g.Nodes()
+
+		for step := 0; step < 300; step++ {
+			a, b := nodes[r.Intn(N)], nodes[r.Intn(N)]
+			if a == b {
+				continue
+			}
+			e := Edge[int]{a, b}
+			if g.edges.Check(e) && r.Intn(2) == 0 {
+				ic.Disconnect(e)
+			} else if _, err := ic.Connect(e); err != nil {
+				t.Fatal(err)
+			}
+
+			checkConsistent(t, g)
+			colors := ic.Colors()
+			if len(colors) != N {
+				t.Fatalf("Round %d step %d: %d of %d nodes colored", round, step, len(colors), N)
+			}
+			if ok, used, conflicts := g.ColoredWith(colors, N); !ok {
+				t.Fatalf("Round %d step %d: coloring with %d colors has conflicts %v", round, step, used, conflicts)
+			}
+			bound := max(initial, g.MaxDegree()+1)
+			if ic.ColorCount() > bound {
+				t.Fatalf("Round %d step %d: %d colors, bound is %d", round, step, ic.ColorCount(), bound)
+			}
+			for _, k := range colors {
+				if k >= bound {
+					t.Fatalf("Round %d step %d: color %d is above the bound %d", round, step, k, bound)
+				}
+			}
+		}
+	}
+}
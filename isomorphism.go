@@ -0,0 +1,344 @@
+// Graph isomorphism and canonical forms
+
+// Isomorphism is tested with a VF2-style search that grows a partial mapping between the graphs.
+// Canonical forms are found by refining node classes by their neighbourhoods and individualizing
+// nodes until every node has its own class, keeping the smallest resulting description
+
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Check whether graphs g and h are isomorphic, meaning there is a one-to-one mapping
+// of their nodes that preserves edges and node values
+// Returns the mapping from the nodes of g to the nodes of h if one exists
+func Isomorphic[T comparable](g, h *Graph[T]) (bool, map[*Node[T]]*Node[T]) {
+
+	if len(g.nodes) != len(h.nodes) || len(g.edges) != len(h.edges) {
+		return false, nil
+	}
+
+	// Nodes can only map to nodes with the same value and degree
+	type nodeClass struct {
+		value  T
+		degree int
+	}
+	classes := make(map[nodeClass]int)
+	for n := range g.nodes {
+		classes[nodeClass{n.value, len(n.neighbours)}]++
+	}
+	for n := range h.nodes {
+		c := nodeClass{n.value, len(n.neighbours)}
+		if classes[c] < 1 {
+			return false, nil
+		}
+		classes[c]--
+	}
+
+	gNodes, hNodes := g.Nodes(), h.Nodes()
+	core1 := make(map[*Node[T]]*Node[T], len(gNodes)) // Partial mapping g -> h
+	core2 := make(map[*Node[T]]*Node[T], len(hNodes)) // Partial mapping h -> g
+	term1 := make(map[*Node[T]]int)                   // Unmapped neighbours of mapped nodes, and the depth they were added
+	term2 := make(map[*Node[T]]int)
+
+	// Check whether the pair (n, m) can extend the current mapping
+	feasible := func(n, m *Node[T]) bool {
+		if n.value != m.value || len(n.neighbours) != len(m.neighbours) {
+			return false
+		}
+
+		mapped, terminal, rest := 0, 0, 0
+		for e := range n.neighbours {
+			u := e.Other(n)
+			if v, ok := core1[u]; ok {
+				// Mapped neighbours must stay neighbours
+				if !m.neighbours.Check(Edge[T]{m, v}) {
+					return false
+				}
+				mapped++
+			} else if term1[u] > 0 {
+				terminal++
+			} else {
+				rest++
+			}
+		}
+
+		// Look ahead: the neighbourhoods must have the same shape relative to the mapping
+		for e := range m.neighbours {
+			u := e.Other(m)
+			if _, ok := core2[u]; ok {
+				mapped--
+			} else if term2[u] > 0 {
+				terminal--
+			} else {
+				rest--
+			}
+		}
+		return mapped == 0 && terminal == 0 && rest == 0
+	}
+
+	// Add the unmapped neighbours of a newly mapped node to the terminal set
+	extend := func(term map[*Node[T]]int, core map[*Node[T]]*Node[T], n *Node[T], depth int) []*Node[T] {
+		added := make([]*Node[T], 0)
+		if term[n] == 0 {
+			term[n] = depth
+			added = append(added, n)
+		}
+		for e := range n.neighbours {
+			u := e.Other(n)
+			if _, ok := core[u]; !ok && term[u] == 0 {
+				term[u] = depth
+				added = append(added, u)
+			}
+		}
+		return added
+	}
+
+	var match func(depth int) bool
+	match = func(depth int) bool {
+		if depth == len(gNodes) {
+			return true
+		}
+
+		// Prefer extending the mapping through the terminal set to keep it connected
+		var n *Node[T]
+		for _, v := range gNodes {
+			if _, ok := core1[v]; !ok && term1[v] > 0 {
+				n = v
+				break
+			}
+		}
+		if n == nil {
+			for _, v := range gNodes {
+				if _, ok := core1[v]; !ok {
+					n = v
+					break
+				}
+			}
+		}
+		inTerminal := term1[n] > 0
+
+		for _, m := range hNodes {
+			if _, ok := core2[m]; ok || (term2[m] > 0) != inTerminal {
+				continue
+			}
+			if !feasible(n, m) {
+				continue
+			}
+
+			core1[n], core2[m] = m, n
+			added1 := extend(term1, core1, n, depth+1)
+			added2 := extend(term2, core2, m, depth+1)
+
+			if match(depth + 1) {
+				return true
+			}
+
+			// Undo the pair
+			delete(core1, n)
+			delete(core2, m)
+			for _, u := range added1 {
+				delete(term1, u)
+			}
+			for _, u := range added2 {
+				delete(term2, u)
+			}
+		}
+		return false
+	}
+
+	if !match(0) {
+		return false, nil
+	}
+	return true, core1
+}
+
+// Compute a canonical labeling of the nodes, numbering them 0..N-1
+// Isomorphic graphs get labelings under which their edges and node values are identical
+func (g *Graph[T]) CanonicalLabeling() map[*Node[T]]int {
+	nodes, labels, _ := g.canonicalize()
+	labeling := make(map[*Node[T]]int, len(nodes))
+	for i, n := range nodes {
+		labeling[n] = labels[i]
+	}
+	return labeling
+}
+
+// Describe the graph in a form that is equal for exactly the graphs isomorphic to it
+func (g *Graph[T]) CanonicalForm() string {
+	_, _, form := g.canonicalize()
+	return form
+}
+
+// Hash of the canonical form, useful for deduplicating stored graphs
+func (g *Graph[T]) CanonicalHash() uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(g.CanonicalForm()))
+	return h.Sum64()
+}
+
+// Search for the canonical labeling, returns the nodes, their labels and the canonical form
+func (g *Graph[T]) canonicalize() ([]*Node[T], []int, string) {
+
+	nodes, _, adjacency := g.adjacencyLists()
+	N := len(nodes)
+
+	values := make([]string, N)
+	for i, n := range nodes {
+		values[i] = strconv.Quote(fmt.Sprint(n.value))
+	}
+
+	// Initial classes are the node values in sorted order
+	distinct := slices.Clone(values)
+	slices.Sort(distinct)
+	distinct = slices.Compact(distinct)
+	colors := make([]int, N)
+	for i, v := range values {
+		colors[i], _ = slices.BinarySearch(distinct, v)
+	}
+
+	// Split classes by the classes of their neighbours until stable
+	// The new classes are ranked so the order of old classes is kept
+	refine := func(colors []int) []int {
+		classes := -1
+		for {
+			signatures := make([][]int, N)
+			for i := range signatures {
+				nb := make([]int, 0, len(adjacency[i])+1)
+				for _, j := range adjacency[i] {
+					nb = append(nb, colors[j])
+				}
+				slices.Sort(nb)
+				signatures[i] = append([]int{colors[i]}, nb...)
+			}
+
+			order := make([]int, N)
+			for i := range order {
+				order[i] = i
+			}
+			sort.Slice(order, func(a, b int) bool {
+				return slices.Compare(signatures[order[a]], signatures[order[b]]) < 0
+			})
+
+			refined := make([]int, N)
+			rank := 0
+			for k, i := range order {
+				if k > 0 && slices.Compare(signatures[order[k-1]], signatures[i]) != 0 {
+					rank++
+				}
+				refined[i] = rank
+			}
+
+			colors = refined
+			if rank+1 == classes {
+				return colors
+			}
+			classes = rank + 1
+		}
+	}
+
+	// Give node v a class of its own, placed before the rest of its class
+	individualize := func(colors []int, v int) []int {
+		next := make([]int, N)
+		for i, c := range colors {
+			next[i] = 2 * c
+		}
+		next[v]--
+		return refine(next)
+	}
+
+	// Describe the graph with nodes relabeled by their class
+	certificate := func(labels []int) string {
+		ordered := make([]string, N)
+		for i, l := range labels {
+			ordered[l] = values[i]
+		}
+		edges := make([][2]int, 0, len(g.edges))
+		for i := range adjacency {
+			for _, j := range adjacency[i] {
+				a, b := labels[i], labels[j]
+				if a < b {
+					edges = append(edges, [2]int{a, b})
+				}
+			}
+		}
+		slices.SortFunc(edges, func(x, y [2]int) int {
+			return slices.Compare(x[:], y[:])
+		})
+
+		strs := make([]string, len(edges))
+		for i, e := range edges {
+			strs[i] = fmt.Sprintf("%d-%d", e[0], e[1])
+		}
+		return fmt.Sprintf("N=%d V=[%s] E=[%s]", N, strings.Join(ordered, " "), strings.Join(strs, " "))
+	}
+
+	var first, best string
+	var firstPath []int
+	var bestLabels []int
+
+	// Depth first search over individualization choices
+	// Returns the depth to jump back to when a subtree is known to be equivalent to one already searched
+	var search func(colors []int, path []int) int
+	search = func(colors []int, path []int) int {
+
+		// Find the first class with more than one node
+		counts := make([]int, N)
+		for _, c := range colors {
+			counts[c]++
+		}
+		target := -1
+		for c, count := range counts {
+			if count > 1 {
+				target = c
+				break
+			}
+		}
+
+		// Every node has its own class, so the classes are a labeling
+		if target < 0 {
+			cert := certificate(colors)
+			if firstPath == nil {
+				first, best = cert, cert
+				firstPath, bestLabels = path, colors
+				return -1
+			}
+			if cert < best {
+				best, bestLabels = cert, colors
+			}
+			if cert == first {
+				// Found an automorphism, the subtree where this path leaves the first path
+				// is a mirror of the one already searched
+				d := 0
+				for d < len(path) && path[d] == firstPath[d] {
+					d++
+				}
+				return d
+			}
+			return -1
+		}
+
+		for v, c := range colors {
+			if c != target {
+				continue
+			}
+			jump := search(individualize(colors, v), append(path[:len(path):len(path)], v))
+			if jump >= 0 && jump < len(path) {
+				return jump
+			}
+		}
+		return -1
+	}
+
+	if N == 0 {
+		return nodes, nil, certificate(nil)
+	}
+	search(refine(colors), make([]int, 0))
+	return nodes, bestLabels, best
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// Graph with N nodes of value 0 and the given edges between node indexes
+func graphFromEdges(N int, edges [][2]int) *Graph[int] {
+	g := NewGraph(N, 0)
+	nodes := g.Nodes()
+	for _, e := range edges {
+		g.Connect(Edge[int]{nodes[e[0]], nodes[e[1]]})
+	}
+	return g
+}
+
+// Copy of the graph with its nodes added in a random order
+func relabeled(g *Graph[int], r *rand.Rand) *Graph[int] {
+	nodes := g.Nodes()
+	h := EmptyGraph[int]()
+	copies := make(map[*Node[int]]*Node[int], len(nodes))
+	for _, i := range r.Perm(len(nodes)) {
+		copies[nodes[i]] = h.AddNodes(1, nodes[i].value)[0]
+	}
+	for e := range g.edges {
+		h.Connect(Edge[int]{copies[e.a], copies[e.b]})
+	}
+	return h
+}
+
+// Check that a mapping is a bijection between the nodes that preserves values and edges
+func checkIsomorphism(t *testing.T, g, h *Graph[int], mapping map[*Node[int]]*Node[int]) {
+	t.Helper()
+	images := make(NodeSet[int])
+	for n := range g.nodes {
+		m, ok := mapping[n]
+		if !ok || !h.nodes[m] || images[m] || m.value != n.value {
+			t.Fatalf("Node %d is not mapped to a distinct node of h with the same value", n.id)
+		}
+		images[m] = true
+	}
+	for e := range g.edges {
+		if !adjacent(mapping[e.a], mapping[e.b]) {
+			t.Fatalf("Edge %v is not preserved by the mapping", e)
+		}
+	}
+}
+
+// Check that two graphs are isomorphic with the same canonical form
+func expectIsomorphic(t *testing.T, g, h *Graph[int]) {
+	t.Helper()
+	ok, mapping := Isomorphic(g, h)
+	if !ok {
+		t.Fatal("Expected the graphs to be isomorphic")
+	}
+	checkIsomorphism(t, g, h, mapping)
+	if g.CanonicalForm() != h.CanonicalForm() || g.CanonicalHash() != h.CanonicalHash() {
+		t.Fatal("Isomorphic graphs have different canonical forms")
+	}
+}
+
+// Check that two graphs are not isomorphic and have different canonical forms
+func expectNotIsomorphic(t *testing.T, g, h *Graph[int]) {
+	t.Helper()
+	if ok, _ := Isomorphic(g, h); ok {
+		t.Fatal("Expected the graphs not to be isomorphic")
+	}
+	if g.CanonicalForm() == h.CanonicalForm() {
+		t.Fatal("Graphs that are not isomorphic have the same canonical form")
+	}
+}
+
+// Cycle of length n starting from node index first
+func cycleEdges(first, n int) [][2]int {
+	edges := make([][2]int, n)
+	for i := range edges {
+		edges[i] = [2]int{first + i, first + (i+1)%n}
+	}
+	return edges
+}
+
+// Petersen graph, an outer 5-cycle joined by spokes to an inner pentagram
+func petersenGraph() *Graph[int] {
+	edges := cycleEdges(0, 5)
+	for i := 0; i < 5; i++ {
+		edges = append(edges, [2]int{i, i + 5}, [2]int{5 + i, 5 + (i+2)%5})
+	}
+	return graphFromEdges(10, edges)
+}
+
+// Prism over a cycle of length n, two n-cycles joined by spokes
+func prismGraph(n int) *Graph[int] {
+	edges := append(cycleEdges(0, n), cycleEdges(n, n)...)
+	for i := 0; i < n; i++ {
+		edges = append(edges, [2]int{i, i + n})
+	}
+	return graphFromEdges(2*n, edges)
+}
+
+// Complete bipartite graph K3,3
+func k33Graph() *Graph[int] {
+	edges := make([][2]int, 0, 9)
+	for a := 0; a < 3; a++ {
+		for b := 3; b < 6; b++ {
+			edges = append(edges, [2]int{a, b})
+		}
+	}
+	return graphFromEdges(6, edges)
+}
+
+func TestIsomorphicRelabeled(t *testing.T) {
+	r := rand.New(rand.NewSource(80))
+	for seed := int64(1); seed <= 300; seed++ {
+		N := 1 + r.Intn(30)
+		g, _ := RandomGraph(N, 0, N+r.Intn(2*N), seed)
+		for n := range g.nodes {
+			n.value = r.Intn(3)
+		}
+		expectIsomorphic(t, g, relabeled(g, r))
+	}
+}
+
+func TestIsomorphicMatchesCanonicalForm(t *testing.T) {
+	r := rand.New(rand.NewSource(81))
+	isomorphic := 0
+	for round := 0; round < 2000; round++ {
+		// Small graphs with the same number of edges are often isomorphic without being copies
+		N := 3 + r.Intn(4)
+		g := randomDenseGraph(r, N, 0.5)
+		h := randomDenseGraph(r, N, 0.5)
+		if len(g.edges) != len(h.edges) {
+			continue
+		}
+		ok, mapping := Isomorphic(g, h)
+		if ok != (g.CanonicalForm() == h.CanonicalForm()) {
+			t.Fatalf("Round %d: isomorphic %v, canonical forms\n%s\n%s", round, ok, g.CanonicalForm(), h.CanonicalForm())
+		}
+		if ok {
+			checkIsomorphism(t, g, h, mapping)
+			isomorphic++
+		}
+	}
+	if isomorphic == 0 {
+		t.Fatal("No isomorphic pairs were generated")
+	}
+}
+
+func TestIsomorphicSameDegrees(t *testing.T) {
+	// A 6-cycle and two triangles are both 2-regular on 6 nodes
+	c6 := graphFromEdges(6, cycleEdges(0, 6))
+	triangles := graphFromEdges(6, append(cycleEdges(0, 3), cycleEdges(3, 3)...))
+	expectNotIsomorphic(t, c6, triangles)
+
+	// The triangular prism is 3-regular like K3,3, and the pentagonal prism like the Petersen graph
+	expectNotIsomorphic(t, k33Graph(), prismGraph(3))
+	expectNotIsomorphic(t, petersenGraph(), prismGraph(5))
+}
+
+func TestIsomorphicSymmetricGraphs(t *testing.T) {
+	r := rand.New(rand.NewSource(82))
+	for round := 0; round < 20; round++ {
+		expectIsomorphic(t, petersenGraph(), relabeled(petersenGraph(), r))
+		expectIsomorphic(t, k33Graph(), relabeled(k33Graph(), r))
+	}
+
+	// Node values have to match too
+	g, h := k33Graph(), k33Graph()
+	h.Nodes()[0].value = 1
+	expectNotIsomorphic(t, g, h)
+}
@@ -19,7 +19,7 @@ func main() {
 	for i, v := range os.Args {
 		switch v {
 		case "-P", "--P":
-			SScanInt(os.Args[i+1], &P, "P")
+			SScanInt(ArgValue(i), &P, "P")
 		}
 	}
 
@@ -30,6 +30,11 @@ func main() {
 		fmt.Println("1. Graph coloring")
 		fmt.Println("2. Local minimum")
 		fmt.Println("3. Largest contiguous submatrix")
+		fmt.Println("4. Register allocation")
+		fmt.Println("5. Sudoku and Latin squares")
+		fmt.Println("6. Exam timetabling")
+		fmt.Println("7. Map coloring")
+		fmt.Println("8. Coloring benchmark")
 		fmt.Print("Program: ")
 		ScanInt(&P, "Program")
 	}
@@ -42,6 +47,16 @@ func main() {
 		RunLocalMinimum()
 	case 3:
 		RunSubmatrix()
+	case 4:
+		RunRegisterAllocation()
+	case 5:
+		RunSudoku()
+	case 6:
+		RunTimetable()
+	case 7:
+		RunMapColor()
+	case 8:
+		RunBenchmark()
 	default:
 		fmt.Println("Not a recognized program")
 		os.Exit(1)
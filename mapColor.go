@@ -0,0 +1,416 @@
+// Map coloring from GeoJSON polygons
+
+// Every feature of the map is a node, and two features are adjacent when their boundaries share
+// a stretch of border, even if one side splits it into more segments than the other. Features
+// touching at a single point are not adjacent. A map of connected regions is planar and four
+// colors are enough, but a feature made of several polygons (an exclave) can need more.
+// The best greedy coloring often manages with four already, otherwise SAT coloring looks for one
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Named area of a map made of polygons, each a list of rings where the first ring is the
+// outer boundary and the rest are holes
+type MapRegion struct {
+	Name     string
+	Polygons [][][][2]float64
+}
+
+// Fill colors for the map
+var mapPalette = []string{"#8dd3c7", "#ffffb3", "#bebada", "#fb8072"}
+
+// Example map for when no input file is given, a wheel of five regions around a center and an island
+const exampleMap = `{"type": "FeatureCollection", "features": [
+{"type": "Feature", "properties": {"name": "Center"}, "geometry": {"type": "Polygon", "coordinates": [[[0.0, 10.0], [-9.5106, 3.0902], [-5.8779, -8.0902], [5.8779, -8.0902], [9.5106, 3.0902], [0.0, 10.0]]]}},
+{"type": "Feature", "properties": {"name": "Northwest"}, "geometry": {"type": "Polygon", "coordinates": [[[0.0, 10.0], [0.0, 25.0], [-23.7764, 7.7254], [-9.5106, 3.0902], [0.0, 10.0]]]}},
+{"type": "Feature", "properties": {"name": "West"}, "geometry": {"type": "Polygon", "coordinates": [[[-9.5106, 3.0902], [-23.7764, 7.7254], [-14.6946, -20.2254], [-5.8779, -8.0902], [-9.5106, 3.0902]]]}},
+{"type": "Feature", "properties": {"name": "South"}, "geometry": {"type": "Polygon", "coordinates": [[[-5.8779, -8.0902], [-14.6946, -20.2254], [14.6946, -20.2254], [5.8779, -8.0902], [-5.8779, -8.0902]]]}},
+{"type": "Feature", "properties": {"name": "East"}, "geometry": {"type": "Polygon", "coordinates": [[[5.8779, -8.0902], [14.6946, -20.2254], [23.7764, 7.7254], [9.5106, 3.0902], [5.8779, -8.0902]]]}},
+{"type": "Feature", "properties": {"name": "Northeast"}, "geometry": {"type": "Polygon", "coordinates": [[[9.5106, 3.0902], [23.7764, 7.7254], [0.0, 25.0], [0.0, 10.0], [9.5106, 3.0902]]]}},
+{"type": "Feature", "properties": {"name": "Island"}, "geometry": {"type": "MultiPolygon", "coordinates": [[[[26.0, 20.0], [30.0, 20.0], [30.0, 24.0], [26.0, 24.0], [26.0, 20.0]]], [[[27.0, 15.0], [29.0, 15.0], [28.0, 17.0], [27.0, 15.0]]]]}}
+]}`
+
+// Read the polygon and multipolygon features of a GeoJSON feature collection
+// Features are named by their "name" property if they have one
+func ReadGeoJSON(r io.Reader) ([]MapRegion, error) {
+	var collection struct {
+		Type     string `json:"type"`
+		Features []struct {
+			Properties map[string]any `json:"properties"`
+			Geometry   *struct {
+				Type        string          `json:"type"`
+				Coordinates json.RawMessage `json:"coordinates"`
+			} `json:"geometry"`
+		} `json:"features"`
+	}
+	if err := json.NewDecoder(r).Decode(&collection); err != nil {
+		return nil, err
+	}
+	if collection.Type != "FeatureCollection" {
+		return nil, fmt.Errorf("Expected a FeatureCollection, got %q", collection.Type)
+	}
+
+	regions := make([]MapRegion, 0, len(collection.Features))
+	for i, f := range collection.Features {
+		region := MapRegion{Name: fmt.Sprintf("Region %d", i+1)}
+		if name, ok := f.Properties["name"].(string); ok {
+			region.Name = name
+		}
+		if f.Geometry == nil {
+			return nil, fmt.Errorf("Feature %d has no geometry", i+1)
+		}
+
+		var polygons [][][][]float64
+		var err error
+		switch f.Geometry.Type {
+		case "Polygon":
+			var polygon [][][]float64
+			err = json.Unmarshal(f.Geometry.Coordinates, &polygon)
+			polygons = [][][][]float64{polygon}
+		case "MultiPolygon":
+			err = json.Unmarshal(f.Geometry.Coordinates, &polygons)
+		default:
+			return nil, fmt.Errorf("Feature %d: unsupported geometry %q", i+1, f.Geometry.Type)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Feature %d: %w", i+1, err)
+		}
+
+		for _, polygon := range polygons {
+			rings := make([][][2]float64, 0, len(polygon))
+			for _, ring := range polygon {
+				if len(ring) < 4 {
+					return nil, fmt.Errorf("Feature %d: ring with %d positions, at least 4 needed", i+1, len(ring))
+				}
+				points := make([][2]float64, len(ring))
+				for j, p := range ring {
+					if len(p) < 2 {
+						return nil, fmt.Errorf("Feature %d: position with %d coordinates", i+1, len(p))
+					}
+					points[j] = [2]float64{p[0], p[1]}
+				}
+				rings = append(rings, points)
+			}
+			region.Polygons = append(region.Polygons, rings)
+		}
+		regions = append(regions, region)
+	}
+
+	if len(regions) == 0 {
+		return nil, errors.New("No features found")
+	}
+	return regions, nil
+}
+
+// Boundary segment with its endpoints in a fixed order, rounded so that
+// the same point written twice with tiny differences still matches
+type segment [2][2]float64
+
+func newSegment(a, b [2]float64) segment {
+	round := func(p [2]float64) [2]float64 {
+		return [2]float64{math.Round(p[0]*1e7) / 1e7, math.Round(p[1]*1e7) / 1e7}
+	}
+	a, b = round(a), round(b)
+	if b[0] < a[0] || (b[0] == a[0] && b[1] < a[1]) {
+		a, b = b, a
+	}
+	return segment{a, b}
+}
+
+// Tolerance for angles and distances when matching segments to the same line
+const borderTolerance = 1e-6
+
+// Boundary segment of a region with the direction of its line in [0, π)
+type borderSegment struct {
+	region int
+	s      segment
+	angle  float64
+}
+
+// Position of point p along the direction of the line at the given angle,
+// and its signed distance from the parallel line through the origin
+func alongLine(p [2]float64, angle float64) (float64, float64) {
+	cos, sin := math.Cos(angle), math.Sin(angle)
+	return p[0]*cos + p[1]*sin, p[1]*cos - p[0]*sin
+}
+
+// Build the adjacency graph of the regions, each node holds its region name
+// Returns the graph and the node of each region
+func MapGraph(regions []MapRegion) (*Graph[string], []*Node[string]) {
+	g := EmptyGraph[string]()
+	nodes := make([]*Node[string], len(regions))
+	segments := make([]borderSegment, 0)
+
+	for i, region := range regions {
+		nodes[i] = g.AddNodes(1, region.Name)[0]
+		for _, polygon := range region.Polygons {
+			for _, ring := range polygon {
+				for j := 1; j < len(ring); j++ {
+					s := newSegment(ring[j-1], ring[j])
+					if s[0] == s[1] {
+						continue
+					}
+					angle := math.Atan2(s[1][1]-s[0][1], s[1][0]-s[0][0])
+					if angle < 0 {
+						angle += math.Pi
+					}
+					if angle > math.Pi-borderTolerance {
+						angle -= math.Pi
+					}
+					segments = append(segments, borderSegment{i, s, angle})
+				}
+			}
+		}
+	}
+
+	// Segments on the same line end up next to each other when sorted by angle, then by distance
+	sort.Slice(segments, func(i, j int) bool { return segments[i].angle < segments[j].angle })
+	for start := 0; start < len(segments); {
+		end := start + 1
+		for end < len(segments) && segments[end].angle-segments[end-1].angle < borderTolerance {
+			end++
+		}
+		connectCollinear(g, nodes, segments[start:end])
+		start = end
+	}
+	return g, nodes
+}
+
+// Connect the regions of segments with (nearly) the same direction whose segments
+// lie on the same line and overlap in more than a single point
+func connectCollinear(g *Graph[string], nodes []*Node[string], segments []borderSegment) {
+	type interval struct {
+		region           int
+		offset, from, to float64
+	}
+
+	// Measure every segment along the direction of the first one so shared endpoints match exactly
+	angle := segments[0].angle
+	intervals := make([]interval, len(segments))
+	for i, b := range segments {
+		from, offset := alongLine(b.s[0], angle)
+		to, _ := alongLine(b.s[1], angle)
+		intervals[i] = interval{b.region, offset, min(from, to), max(from, to)}
+	}
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i].offset < intervals[j].offset })
+
+	for start := 0; start < len(intervals); {
+		end := start + 1
+		for end < len(intervals) && intervals[end].offset-intervals[end-1].offset < borderTolerance {
+			end++
+		}
+
+		// Sweep the segments of one line by their start, keeping those not yet ended
+		line := intervals[start:end]
+		sort.Slice(line, func(i, j int) bool { return line[i].from < line[j].from })
+		active := make([]interval, 0)
+		for _, next := range line {
+			kept := active[:0]
+			for _, a := range active {
+				if a.to > next.from {
+					kept = append(kept, a)
+					if a.region != next.region {
+						g.Connect(Edge[string]{nodes[a.region], nodes[next.region]})
+					}
+				}
+			}
+			active = append(kept, next)
+		}
+		start = end
+	}
+}
+
+// Color the map with at most four colors
+// On timeout, or if exclaves make four colors too few, the best greedy coloring is returned with the error
+func ColorMap(ctx context.Context, g *Graph[string]) (ColorAssignment[string], error) {
+	assignment, used, _ := g.ColorGreedyBest()
+	if used <= len(mapPalette) {
+		return assignment, nil
+	}
+	four, err := g.ColorSATContext(ctx, len(mapPalette), ColorConstraints[string]{})
+	if err != nil {
+		return assignment, err
+	}
+	return four, nil
+}
+
+// Draw the regions as SVG with the given width, filled with their colors
+// Longitude and latitude are drawn as x and y
+func WriteMapSVG(w io.Writer, regions []MapRegion, fills []string, width float64) error {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, region := range regions {
+		for _, polygon := range region.Polygons {
+			for _, ring := range polygon {
+				for _, p := range ring {
+					minX, maxX = math.Min(minX, p[0]), math.Max(maxX, p[0])
+					minY, maxY = math.Min(minY, p[1]), math.Max(maxY, p[1])
+				}
+			}
+		}
+	}
+
+	if math.IsInf(minX, 1) {
+		minX, minY, maxX, maxY = 0, 0, 0, 0
+	}
+
+	const margin = 10.0
+	scale := (width - 2*margin) / math.Max(maxX-minX, 1e-9)
+	height := (maxY-minY)*scale + 2*margin
+	project := func(p [2]float64) string {
+		return fmt.Sprintf("%.2f,%.2f", margin+(p[0]-minX)*scale, margin+(maxY-p[1])*scale)
+	}
+
+	fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" viewBox="0 0 %.0f %.0f">`+"\n", width, height, width, height)
+	for i, region := range regions {
+		var path strings.Builder
+		for _, polygon := range region.Polygons {
+			for _, ring := range polygon {
+				for j, p := range ring {
+					if j == 0 {
+						path.WriteString("M")
+					} else {
+						path.WriteString(" L")
+					}
+					path.WriteString(project(p))
+				}
+				path.WriteString(" Z ")
+			}
+		}
+		fmt.Fprintf(w, `<path d="%s" fill="%s" fill-rule="evenodd" stroke="#333" stroke-width="0.5"><title>%s</title></path>`+"\n",
+			strings.TrimSpace(path.String()), fills[i], xmlEscape(region.Name))
+	}
+	_, err := fmt.Fprintln(w, "</svg>")
+	return err
+}
+
+// Escape text for XML
+func xmlEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;").Replace(s)
+}
+
+// Program to demonstrate coloring a map with four colors
+func RunMapColor() {
+	var In string                  // Input GeoJSON file
+	var Out string                 // Output SVG file
+	var noPrint bool               // Do not print the adjacencies
+	var noVisuals bool             // Do not display the map
+	var noSave bool                // Disable save prompt
+	var timeout = 10 * time.Second // Time budget for SAT coloring
+
+	// Extract args
+	for i, v := range os.Args {
+		switch v {
+		case "-I", "--I":
+			fmt.Sscanf(ArgValue(i), "%s", &In)
+		case "-O", "--O":
+			fmt.Sscanf(ArgValue(i), "%s", &Out)
+		case "--timeout":
+			SScanDuration(ArgValue(i), &timeout, "timeout")
+		case "--noprint":
+			noPrint = true
+		case "--novisuals":
+			noVisuals = true
+		case "--nosave":
+			noSave = true
+		}
+	}
+
+	fmt.Println("---- Map coloring program ----")
+
+	var source io.Reader = strings.NewReader(exampleMap)
+	if len(In) > 0 {
+		file, err := os.Open(In)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		defer file.Close()
+		source = file
+	} else {
+		fmt.Println("No input file given, using the example map")
+	}
+
+	regions, err := ReadGeoJSON(source)
+	if err != nil {
+		fmt.Println("Unable to read the map:", err)
+		os.Exit(1)
+	}
+
+	graph, nodes := MapGraph(regions)
+	fmt.Printf("---- Map of %d regions with %d borders ----\n", len(regions), len(graph.edges))
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	assignment, err := ColorMap(ctx, graph)
+	if err != nil {
+		fmt.Println("Unable to color the map with four colors:", err)
+	}
+
+	isColored, ncolors, _ := graph.ColoredWith(assignment, len(mapPalette))
+	fmt.Println("Colored:", isColored, "\nColors used:", ncolors)
+
+	fills := make([]string, len(regions))
+	for i, n := range nodes {
+		fills[i] = "#cccccc"
+		if c := assignment[n]; c < len(mapPalette) {
+			fills[i] = mapPalette[c]
+		}
+	}
+
+	if noPrint {
+		fmt.Println("--noprint specified, skip adjacency printing")
+	} else {
+		for i, n := range nodes {
+			neighbours := make(map[string]bool)
+			for e := range n.neighbours {
+				neighbours[e.Other(n).value] = true
+			}
+			fmt.Printf("%s (%s): %s\n", regions[i].Name, fills[i], strings.Join(sortedNames(neighbours), ", "))
+		}
+	}
+
+	var svg strings.Builder
+	WriteMapSVG(&svg, regions, fills, 800)
+
+	if !noSave && len(Out) < 1 {
+		saveMap := "n"
+		fmt.Println("Save map to file? (y/n)")
+		fmt.Scanf("%s\n", &saveMap)
+		if saveMap == "y" {
+			fmt.Print("Filename: ")
+			fmt.Scanf("%s\n", &Out)
+		}
+	}
+
+	if !noSave && len(Out) > 0 {
+		if err := os.WriteFile(Out, []byte(svg.String()), 0644); err != nil {
+			fmt.Println(err)
+		} else {
+			fmt.Printf("Saved to %s\n", Out)
+		}
+	}
+
+	if noVisuals {
+		fmt.Println("--novisuals specified")
+	} else {
+		visualize := "n"
+		fmt.Println("Display the map in a browser? (y/n)")
+		fmt.Scanf("%s\n", &visualize)
+		if visualize == "y" {
+			PlotSVG("Map coloring", svg.String())
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// Closed ring through the given points
+func ring(points ...[2]float64) [][2]float64 {
+	return append(points, points[0])
+}
+
+// Unit square with its lower left corner at (x, y)
+func unitSquare(x, y float64) [][][2]float64 {
+	return [][][2]float64{ring([2]float64{x, y}, [2]float64{x + 1, y}, [2]float64{x + 1, y + 1}, [2]float64{x, y + 1})}
+}
+
+// Check that exactly the expected pairs of regions are adjacent
+func checkAdjacent(t *testing.T, regions []MapRegion, expected map[[2]string]bool) {
+	t.Helper()
+	g, nodes := MapGraph(regions)
+	for i, a := range nodes {
+		for _, b := range nodes[i+1:] {
+			pair := [2]string{a.value, b.value}
+			if adjacent(a, b) != (expected[pair] || expected[[2]string{pair[1], pair[0]}]) {
+				t.Errorf("Regions %s and %s adjacent %v", a.value, b.value, adjacent(a, b))
+			}
+		}
+	}
+	if len(g.edges) != len(expected) {
+		t.Errorf("Map has %d borders, expected %d", len(g.edges), len(expected))
+	}
+}
+
+func TestMapGraphSharedBorders(t *testing.T) {
+	// The top of Bottom is one segment, Left and Right split it at a T-junction
+	// Corner only touches Bottom at a point
+	regions := []MapRegion{
+		{"Bottom", [][][][2]float64{{ring([2]float64{0, 0}, [2]float64{2, 0}, [2]float64{2, 1}, [2]float64{0, 1})}}},
+		{"Left", [][][][2]float64{unitSquare(0, 1)}},
+		{"Right", [][][][2]float64{unitSquare(1, 1)}},
+		{"Corner", [][][][2]float64{unitSquare(2, -1)}},
+	}
+	checkAdjacent(t, regions, map[[2]string]bool{
+		{"Bottom", "Left"}: true, {"Bottom", "Right"}: true, {"Left", "Right"}: true,
+	})
+}
+
+func TestMapGraphDiagonalOverlap(t *testing.T) {
+	// Inner shares part of the diagonal of Outer, Touching meets Outer's diagonal at its end only
+	regions := []MapRegion{
+		{"Outer", [][][][2]float64{{ring([2]float64{0, 0}, [2]float64{4, 0}, [2]float64{4, 4})}}},
+		{"Inner", [][][][2]float64{{ring([2]float64{1, 1}, [2]float64{3, 3}, [2]float64{0, 4})}}},
+		{"Touching", [][][][2]float64{{ring([2]float64{4, 4}, [2]float64{5, 5}, [2]float64{3, 6})}}},
+	}
+	checkAdjacent(t, regions, map[[2]string]bool{{"Outer", "Inner"}: true})
+}
+
+func TestMapGraphExample(t *testing.T) {
+	regions, err := ReadGeoJSON(strings.NewReader(exampleMap))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wheel := []string{"Northwest", "West", "South", "East", "Northeast"}
+	expected := make(map[[2]string]bool)
+	for i, name := range wheel {
+		expected[[2]string{"Center", name}] = true
+		expected[[2]string{name, wheel[(i+1)%len(wheel)]}] = true
+	}
+	checkAdjacent(t, regions, expected)
+
+	// A center surrounded by an odd ring needs all four colors
+	g, _ := MapGraph(regions)
+	assignment, err := ColorMap(context.Background(), g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, used, conflicts := g.ColoredWith(assignment, len(mapPalette)); !ok || used != 4 {
+		t.Fatalf("Map colored %v with %d colors and conflicts %v", ok, used, conflicts)
+	}
+}
+
+func TestColorMapExclaves(t *testing.T) {
+	// Squares in a row where every pair of five regions is next to each other somewhere,
+	// so the regions form a complete graph and four colors are not enough
+	order := []int{0, 1, 2, 3, 4, 0, 2, 4, 1, 3, 0}
+	regions := make([]MapRegion, 5)
+	for i := range regions {
+		regions[i].Name = string(rune('A' + i))
+	}
+	for x, r := range order {
+		regions[r].Polygons = append(regions[r].Polygons, unitSquare(float64(x), 0))
+	}
+
+	g, _ := MapGraph(regions)
+	if len(g.edges) != 10 {
+		t.Fatalf("Map has %d borders, expected all 10 pairs", len(g.edges))
+	}
+	assignment, err := ColorMap(context.Background(), g)
+	if !errors.Is(err, ErrNotColorable) {
+		t.Fatalf("Expected ErrNotColorable, got %v", err)
+	}
+	if ok, used, _ := g.ColoredWith(assignment, 5); !ok || used != 5 {
+		t.Fatalf("Fallback coloring is %v with %d colors, expected a proper 5-coloring", ok, used)
+	}
+}
@@ -0,0 +1,281 @@
+// Metaheuristic graph colorers for instances where backtracking takes too long
+
+// Both start from a random k-coloring and move single nodes to other colors to reduce
+// the number of conflict edges. Tabu search (TabuCol) always makes the best move that isn't
+// forbidden by a recent move, while simulated annealing accepts random worsening moves
+// with a probability that decreases as the temperature cools
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// Iterations between checks for cancellation
+const contextCheckInterval = 1024
+
+// Best conflict count found by the iteration it was reached
+type ProgressPoint struct {
+	Iteration int
+	Conflicts int
+}
+
+// Parameters for tabu search
+// A node moved away from a color can't return to it for Tenure + TenureFactor * (conflicting nodes) iterations,
+// plus a random amount below Tenure
+type TabuOptions struct {
+	Tenure        int
+	TenureFactor  float64
+	MaxIterations int
+}
+
+// Parameters for simulated annealing
+// The temperature is multiplied by Cooling after every iteration, and the search stops below MinTemperature
+type AnnealOptions struct {
+	StartTemperature float64
+	MinTemperature   float64
+	Cooling          float64
+	MaxIterations    int
+}
+
+// Reasonable defaults for tabu search
+func DefaultTabuOptions() TabuOptions {
+	return TabuOptions{Tenure: 10, TenureFactor: 0.6, MaxIterations: 100000}
+}
+
+// Reasonable defaults for simulated annealing
+func DefaultAnnealOptions() AnnealOptions {
+	return AnnealOptions{StartTemperature: 2.0, MinTemperature: 0.001, Cooling: 0.99999, MaxIterations: 1000000}
+}
+
+// Coloring of numbered nodes that keeps track of the conflicts
+type conflictState struct {
+	adjacency [][]int
+	colors    []int
+	gamma     [][]int // Number of neighbours of each node with each color
+	conflicts int     // Number of conflict edges
+	list      []int   // Nodes with at least one conflict
+	position  []int   // Position of each node in the list, -1 if not in the list
+}
+
+// Start tracking conflicts of a coloring with k colors
+func newConflictState(adjacency [][]int, k int, colors []int) *conflictState {
+	s := &conflictState{
+		adjacency: adjacency,
+		colors:    colors,
+		gamma:     make([][]int, len(adjacency)),
+		list:      make([]int, 0),
+		position:  make([]int, len(adjacency)),
+	}
+	for i := range adjacency {
+		s.gamma[i] = make([]int, k)
+		for _, j := range adjacency[i] {
+			s.gamma[i][colors[j]]++
+		}
+		s.conflicts += s.gamma[i][colors[i]]
+		s.position[i] = -1
+		s.update(i)
+	}
+	s.conflicts /= 2
+	return s
+}
+
+// Add or remove node i from the conflict list
+func (s *conflictState) update(i int) {
+	conflicted := s.gamma[i][s.colors[i]] > 0
+	if conflicted && s.position[i] < 0 {
+		s.position[i] = len(s.list)
+		s.list = append(s.list, i)
+	} else if !conflicted && s.position[i] >= 0 {
+		last := s.list[len(s.list)-1]
+		s.list[s.position[i]] = last
+		s.position[last] = s.position[i]
+		s.list = s.list[:len(s.list)-1]
+		s.position[i] = -1
+	}
+}
+
+// Change in conflicts if node i is moved to color c
+func (s *conflictState) delta(i, c int) int {
+	return s.gamma[i][c] - s.gamma[i][s.colors[i]]
+}
+
+// Move node i to color c
+func (s *conflictState) move(i, c int) {
+	old := s.colors[i]
+	s.conflicts += s.delta(i, c)
+	s.colors[i] = c
+	for _, j := range s.adjacency[i] {
+		s.gamma[j][old]--
+		s.gamma[j][c]++
+		s.update(j)
+	}
+	s.update(i)
+}
+
+// Random initial coloring of the graph nodes with k colors
+func (g *Graph[T]) randomConflictState(k int, r *rand.Rand) ([]*Node[T], *conflictState) {
+	nodes, _, adjacency := g.adjacencyLists()
+	colors := make([]int, len(nodes))
+	for i := range colors {
+		colors[i] = r.Intn(k)
+	}
+	return nodes, newConflictState(adjacency, k, colors)
+}
+
+// Build an assignment from the best coloring and report whether conflicts remain
+func searchResult[T comparable](ctx context.Context, nodes []*Node[T], best []int, conflicts int, name string) (ColorAssignment[T], error) {
+	assignment := make(ColorAssignment[T], len(nodes))
+	for i, n := range nodes {
+		assignment[n] = best[i]
+	}
+	if conflicts > 0 && ctx.Err() != nil {
+		return assignment, timeoutError(ctx)
+	}
+	if conflicts > 0 {
+		return assignment, fmt.Errorf("%s ended with %d conflicts", name, conflicts)
+	}
+	return assignment, nil
+}
+
+// Color a graph G with k colors using tabu search
+// Returns the best coloring found and the best conflict count over time
+func (g *Graph[T]) ColorTabu(k int, seed int64, opts TabuOptions) (ColorAssignment[T], []ProgressPoint, error) {
+	return g.ColorTabuContext(context.Background(), k, seed, opts)
+}
+
+// Color a graph G with tabu search, stopping when the context is done
+func (g *Graph[T]) ColorTabuContext(ctx context.Context, k int, seed int64, opts TabuOptions) (ColorAssignment[T], []ProgressPoint, error) {
+
+	if g.root == nil {
+		return nil, nil, errors.New("Graph is empty")
+	}
+
+	if k < 1 || (k < 2 && len(g.edges) > 0) {
+		return nil, nil, errors.New("Not enough colors to color the graph")
+	}
+
+	r := rand.New(rand.NewSource(seed))
+	nodes, s := g.randomConflictState(k, r)
+
+	// Iteration until which moving a node back to a color is forbidden
+	tabu := make([][]int, len(nodes))
+	for i := range tabu {
+		tabu[i] = make([]int, k)
+	}
+
+	best := append([]int(nil), s.colors...)
+	bestConflicts := s.conflicts
+	progress := []ProgressPoint{{0, bestConflicts}}
+
+	for iter := 1; iter <= opts.MaxIterations && bestConflicts > 0; iter++ {
+		if iter%contextCheckInterval == 0 && ctx.Err() != nil {
+			break
+		}
+
+		// Find the best allowed move of a conflicting node, breaking ties randomly
+		moveNode, moveColor, moveDelta, ties := -1, -1, 0, 0
+		for _, i := range s.list {
+			for c := 0; c < k; c++ {
+				if c == s.colors[i] {
+					continue
+				}
+				d := s.delta(i, c)
+
+				// Tabu moves are allowed when they lead to a new best coloring
+				if tabu[i][c] > iter && s.conflicts+d >= bestConflicts {
+					continue
+				}
+
+				if moveNode < 0 || d < moveDelta {
+					moveNode, moveColor, moveDelta, ties = i, c, d, 1
+				} else if d == moveDelta {
+					ties++
+					if r.Intn(ties) == 0 {
+						moveNode, moveColor = i, c
+					}
+				}
+			}
+		}
+
+		// Every move is tabu
+		if moveNode < 0 {
+			continue
+		}
+
+		old := s.colors[moveNode]
+		s.move(moveNode, moveColor)
+		tenure := opts.Tenure + int(opts.TenureFactor*float64(len(s.list)))
+		if opts.Tenure > 0 {
+			tenure += r.Intn(opts.Tenure)
+		}
+		tabu[moveNode][old] = iter + tenure
+
+		if s.conflicts < bestConflicts {
+			bestConflicts = s.conflicts
+			copy(best, s.colors)
+			progress = append(progress, ProgressPoint{iter, bestConflicts})
+		}
+	}
+
+	assignment, err := searchResult(ctx, nodes, best, bestConflicts, "Tabu search")
+	return assignment, progress, err
+}
+
+// Color a graph G with k colors using simulated annealing
+// Returns the best coloring found and the best conflict count over time
+func (g *Graph[T]) ColorAnneal(k int, seed int64, opts AnnealOptions) (ColorAssignment[T], []ProgressPoint, error) {
+	return g.ColorAnnealContext(context.Background(), k, seed, opts)
+}
+
+// Color a graph G with simulated annealing, stopping when the context is done
+func (g *Graph[T]) ColorAnnealContext(ctx context.Context, k int, seed int64, opts AnnealOptions) (ColorAssignment[T], []ProgressPoint, error) {
+
+	if g.root == nil {
+		return nil, nil, errors.New("Graph is empty")
+	}
+
+	if k < 1 || (k < 2 && len(g.edges) > 0) {
+		return nil, nil, errors.New("Not enough colors to color the graph")
+	}
+
+	r := rand.New(rand.NewSource(seed))
+	nodes, s := g.randomConflictState(k, r)
+
+	best := append([]int(nil), s.colors...)
+	bestConflicts := s.conflicts
+	progress := []ProgressPoint{{0, bestConflicts}}
+
+	temperature := opts.StartTemperature
+	for iter := 1; iter <= opts.MaxIterations && bestConflicts > 0 && temperature > opts.MinTemperature; iter++ {
+		if iter%contextCheckInterval == 0 && ctx.Err() != nil {
+			break
+		}
+
+		// Only moving a conflicting node can remove conflicts
+		i := s.list[r.Intn(len(s.list))]
+		c := r.Intn(k - 1)
+		if c >= s.colors[i] {
+			c++
+		}
+
+		// Always accept improvements, and worse moves with a chance based on the temperature
+		if d := s.delta(i, c); d <= 0 || r.Float64() < math.Exp(-float64(d)/temperature) {
+			s.move(i, c)
+		}
+		temperature *= opts.Cooling
+
+		if s.conflicts < bestConflicts {
+			bestConflicts = s.conflicts
+			copy(best, s.colors)
+			progress = append(progress, ProgressPoint{iter, bestConflicts})
+		}
+	}
+
+	assignment, err := searchResult(ctx, nodes, best, bestConflicts, "Simulated annealing")
+	return assignment, progress, err
+}
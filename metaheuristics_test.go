@@ -0,0 +1,98 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// Random graph with N nodes split into k hidden classes, where each pair of nodes
+// in different classes is connected with probability p, so k colors are enough
+func plantedPartitionGraph(r *rand.Rand, N, k int, p float64) *Graph[int] {
+	g := NewGraph(N, 0)
+	nodes := g.Nodes()
+	class := make([]int, N)
+	for i := range class {
+		class[i] = r.Intn(k)
+	}
+	for i, a := range nodes {
+		for j := i + 1; j < N; j++ {
+			if class[i] != class[j] && r.Float64() < p {
+				g.Connect(Edge[int]{a, nodes[j]})
+			}
+		}
+	}
+	return g
+}
+
+// Metaheuristic colorer under test
+type searchColorer func(g *Graph[int], k int, seed int64) (ColorAssignment[int], []ProgressPoint, error)
+
+var searchColorers = map[string]searchColorer{
+	"tabu": func(g *Graph[int], k int, seed int64) (ColorAssignment[int], []ProgressPoint, error) {
+		return g.ColorTabu(k, seed, DefaultTabuOptions())
+	},
+	"anneal": func(g *Graph[int], k int, seed int64) (ColorAssignment[int], []ProgressPoint, error) {
+		return g.ColorAnneal(k, seed, DefaultAnnealOptions())
+	},
+}
+
+// Check that the last progress point reports the conflicts of the returned coloring
+func checkProgress(t *testing.T, g *Graph[int], a ColorAssignment[int], progress []ProgressPoint, k int) int {
+	t.Helper()
+	_, _, conflicts := g.ColoredWith(a, k)
+	last := progress[len(progress)-1]
+	if last.Conflicts != len(conflicts) {
+		t.Fatalf("Best conflict count %d, the returned coloring has %d", last.Conflicts, len(conflicts))
+	}
+	for i := 1; i < len(progress); i++ {
+		if progress[i].Conflicts >= progress[i-1].Conflicts || progress[i].Iteration <= progress[i-1].Iteration {
+			t.Fatalf("Progress %v doesn't improve at every point", progress)
+		}
+	}
+	return len(conflicts)
+}
+
+func TestSearchColorersPlantedPartition(t *testing.T) {
+	for name, color := range searchColorers {
+		r := rand.New(rand.NewSource(60))
+		for seed := int64(1); seed <= 30; seed++ {
+			k := 2 + r.Intn(3)
+			var g *Graph[int]
+			if k == 2 {
+				g = randomBipartiteGraph(r, 5+r.Intn(30), 5+r.Intn(30), 0.2)
+			} else {
+				g = plantedPartitionGraph(r, 20+r.Intn(40), k, 0.3)
+			}
+
+			a, progress, err := color(g, k, seed)
+			if err != nil {
+				t.Fatalf("%s, seed %d: %d-colorable graph failed: %v", name, seed, k, err)
+			}
+			if conflicts := checkProgress(t, g, a, progress, k); conflicts != 0 {
+				t.Fatalf("%s, seed %d: %d conflicts left", name, seed, conflicts)
+			}
+			if ok, _, _ := g.ColoredWith(a, k); !ok {
+				t.Fatalf("%s, seed %d: improper coloring", name, seed)
+			}
+		}
+	}
+}
+
+func TestSearchColorersTooFewColors(t *testing.T) {
+	for name, color := range searchColorers {
+		r := rand.New(rand.NewSource(61))
+		for seed := int64(1); seed <= 10; seed++ {
+			// A clique of k+1 nodes always leaves a conflict
+			k := 2 + r.Intn(3)
+			g := CompleteGraph(k+1+r.Intn(3), 0)
+
+			a, progress, err := color(g, k, seed)
+			if err == nil {
+				t.Fatalf("%s, seed %d: expected %d colors to fail on a clique of %d", name, seed, k, len(g.nodes))
+			}
+			if conflicts := checkProgress(t, g, a, progress, k); conflicts == 0 {
+				t.Fatalf("%s, seed %d: no conflicts reported for an impossible coloring", name, seed)
+			}
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// Observing the graph coloring algorithm while it runs
+
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// Receives events from the tree-then-resolve coloring algorithm
+type ColorObserver interface {
+	TreeBuilt(conflicts int)      // The two-colored tree is built, with the number of conflict edges to resolve
+	Pass(pass, incorrect int)     // A conflict resolution pass ended with the number of nodes left without a color
+	Backtrack(depth, backlog int) // Backtracked to the choice at depth in the backlog, with the new backlog length
+}
+
+// Observers implementing this also receive every color assignment, including the initial tree coloring
+type AssignObserver[T comparable] interface {
+	Assign(node *Node[T], color int)
+}
+
+// Observer that ignores all events
+type nopObserver struct{}
+
+func (nopObserver) TreeBuilt(int)      {}
+func (nopObserver) Pass(int, int)      {}
+func (nopObserver) Backtrack(int, int) {}
+
+// Observer that prints the events
+type ConsoleObserver struct {
+	Out io.Writer
+}
+
+// Print the number of conflict edges after the tree is built
+func (o ConsoleObserver) TreeBuilt(conflicts int) {
+	fmt.Fprintf(o.Out, "Tree built, conflict edges: %d\n", conflicts)
+}
+
+// Print the result of a resolution pass
+func (o ConsoleObserver) Pass(pass, incorrect int) {
+	fmt.Fprintf(o.Out, "Pass %d, incorrect: %d\n", pass, incorrect)
+}
+
+// Print a backtrack event
+func (o ConsoleObserver) Backtrack(depth, backlog int) {
+	fmt.Fprintf(o.Out, "Backtrack to depth %d, backlog: %d\n", depth, backlog)
+}
+
+// Observer that passes events on to several observers
+type MultiObserver[T comparable] []ColorObserver
+
+// Pass on the tree built event
+func (m MultiObserver[T]) TreeBuilt(conflicts int) {
+	for _, o := range m {
+		o.TreeBuilt(conflicts)
+	}
+}
+
+// Pass on the pass event
+func (m MultiObserver[T]) Pass(pass, incorrect int) {
+	for _, o := range m {
+		o.Pass(pass, incorrect)
+	}
+}
+
+// Pass on the backtrack event
+func (m MultiObserver[T]) Backtrack(depth, backlog int) {
+	for _, o := range m {
+		o.Backtrack(depth, backlog)
+	}
+}
+
+// Pass on the assignment to the observers following assignments
+func (m MultiObserver[T]) Assign(node *Node[T], color int) {
+	for _, o := range m {
+		if a, ok := o.(AssignObserver[T]); ok {
+			a.Assign(node, color)
+		}
+	}
+}
+
+// Kinds of recorded trace steps
+const (
+	TraceAssign    = "assign"
+	TraceTree      = "tree"
+	TracePass      = "pass"
+	TraceBacktrack = "backtrack"
+)
+
+// A single recorded event of the coloring algorithm
+// Assign steps have the node and its new color, tree steps have the conflict edge count,
+// pass steps have the incorrect count and backtrack steps have the backlog depth as the value
+type TraceStep[T comparable] struct {
+	Kind  string
+	Node  *Node[T]
+	Color int
+	Value int
+}
+
+// Observer that records every event so the run can be replayed
+type ColorTrace[T comparable] struct {
+	Steps []TraceStep[T]
+}
+
+// Record the tree built event, the tree colors are already recorded as assignments
+func (t *ColorTrace[T]) TreeBuilt(conflicts int) {
+	t.Steps = append(t.Steps, TraceStep[T]{Kind: TraceTree, Value: conflicts})
+}
+
+// Record the end of a pass
+func (t *ColorTrace[T]) Pass(pass, incorrect int) {
+	t.Steps = append(t.Steps, TraceStep[T]{Kind: TracePass, Value: incorrect})
+}
+
+// Record a backtrack
+func (t *ColorTrace[T]) Backtrack(depth, backlog int) {
+	t.Steps = append(t.Steps, TraceStep[T]{Kind: TraceBacktrack, Value: depth})
+}
+
+// Record a color assignment
+func (t *ColorTrace[T]) Assign(node *Node[T], color int) {
+	t.Steps = append(t.Steps, TraceStep[T]{Kind: TraceAssign, Node: node, Color: color})
+}
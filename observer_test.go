@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestColorTraceKinds(t *testing.T) {
+	g, _ := RandomGraph(30, 0, 70, 16)
+	trace := &ColorTrace[int]{}
+	if _, err := g.ColorObserved(context.Background(), 4, trace); err != nil {
+		t.Fatal(err)
+	}
+
+	// The tree is built once, after its assignments and before any pass
+	trees, passes := 0, 0
+	for _, step := range trace.Steps {
+		switch step.Kind {
+		case TraceTree:
+			if passes > 0 {
+				t.Fatal("Tree step recorded after a pass")
+			}
+			trees++
+		case TracePass:
+			if trees == 0 {
+				t.Fatal("Pass recorded before the tree step")
+			}
+			passes++
+		}
+	}
+	if trees != 1 {
+		t.Errorf("Recorded %d tree steps, expected 1", trees)
+	}
+	if trace.Steps[0].Kind != TraceAssign {
+		t.Errorf("Trace starts with a %s step instead of the tree coloring", trace.Steps[0].Kind)
+	}
+}
@@ -0,0 +1,135 @@
+// Parallel graph coloring with the Jones-Plassmann algorithm
+
+// Every node gets a random priority. On each round the uncolored nodes whose priority is higher
+// than that of all their uncolored neighbours form an independent set, so they can all be
+// given their smallest free color at the same time
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+)
+
+// Color a graph G with max c colors using a number of worker goroutines
+// The seed decides the node priorities, so the result is the same for any number of workers
+func (g *Graph[T]) ColorParallel(c int, workers int, seed int64) (ColorAssignment[T], error) {
+	return g.ColorParallelContext(context.Background(), c, workers, seed)
+}
+
+// Color a graph G in parallel, stopping between rounds when the context is done
+// On timeout, the nodes colored so far are returned with ErrTimeout
+func (g *Graph[T]) ColorParallelContext(ctx context.Context, c int, workers int, seed int64) (ColorAssignment[T], error) {
+
+	if g.root == nil {
+		return nil, errors.New("Graph is empty")
+	}
+
+	if workers < 1 {
+		workers = 1
+	}
+
+	nodes, _, adjacency := g.adjacencyLists()
+	N := len(nodes)
+
+	// Higher degree nodes go first, the random permutation breaks ties
+	r := rand.New(rand.NewSource(seed))
+	priority := r.Perm(N)
+	for i := range priority {
+		priority[i] += N * len(adjacency[i])
+	}
+
+	colors := make([]int, N)
+	for i := range colors {
+		colors[i] = -1
+	}
+
+	// Run f over the given nodes split into chunks, one chunk per worker
+	parallel := func(active []int, f func(i int)) {
+		var wg sync.WaitGroup
+		chunk := (len(active) + workers - 1) / workers
+		for start := 0; start < len(active); start += chunk {
+			end := min(start+chunk, len(active))
+			wg.Add(1)
+			go func(part []int) {
+				defer wg.Done()
+				for _, i := range part {
+					f(i)
+				}
+			}(active[start:end])
+		}
+		wg.Wait()
+	}
+
+	active := make([]int, N)
+	for i := range active {
+		active[i] = i
+	}
+	selected := make([]bool, N)
+
+	var err error
+	for len(active) > 0 {
+		if ctx.Err() != nil {
+			err = timeoutError(ctx)
+			break
+		}
+
+		// Select the local maxima, reading only colors from previous rounds
+		parallel(active, func(i int) {
+			selected[i] = true
+			for _, j := range adjacency[i] {
+				if colors[j] < 0 && priority[j] > priority[i] {
+					selected[i] = false
+					return
+				}
+			}
+		})
+
+		// Color the selected nodes, no two of them are neighbours
+		parallel(active, func(i int) {
+			if !selected[i] {
+				return
+			}
+			used := make([]bool, len(adjacency[i])+1)
+			for _, j := range adjacency[i] {
+				if k := colors[j]; k >= 0 && k < len(used) {
+					used[k] = true
+				}
+			}
+			k := 0
+			for used[k] {
+				k++
+			}
+			colors[i] = k
+		})
+
+		remaining := active[:0]
+		for _, i := range active {
+			if colors[i] < 0 {
+				remaining = append(remaining, i)
+			}
+		}
+		active = remaining
+	}
+
+	assignment := make(ColorAssignment[T], N)
+	used := 0
+	for i, n := range nodes {
+		if colors[i] >= 0 {
+			assignment[n] = colors[i]
+			used = max(used, colors[i]+1)
+		}
+	}
+
+	if err != nil {
+		return assignment, err
+	}
+
+	if used > c {
+		return assignment, fmt.Errorf("Parallel coloring used %d colors, more than %d", used, c)
+	}
+	return assignment, nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// Run with -race to check that the workers of a round never touch the same node
+func TestColorParallel(t *testing.T) {
+	r := rand.New(rand.NewSource(50))
+	for seed := int64(1); seed <= 100; seed++ {
+		N := 2 + r.Intn(200)
+		g, _ := RandomGraph(N, 0, N+r.Intn(4*N), seed)
+
+		// Every node takes its smallest free color, so D+1 colors are always enough
+		c := g.MaxDegree() + 1
+		var first ColorAssignment[int]
+		for _, workers := range []int{1, 2, 3, 8, 16} {
+			a, err := g.ColorParallel(c, workers, seed)
+			if err != nil {
+				t.Fatalf("Seed %d: %d workers failed: %v", seed, workers, err)
+			}
+			if ok, used, conflicts := g.ColoredWith(a, c); !ok {
+				t.Fatalf("Seed %d: %d workers colored with %d colors and conflicts %v", seed, workers, used, conflicts)
+			}
+
+			// The seed alone decides the coloring
+			if first == nil {
+				first = a
+				continue
+			}
+			for n, color := range first {
+				if a[n] != color {
+					t.Fatalf("Seed %d: node %d has color %d with %d workers and %d with one", seed, n.id, a[n], workers, color)
+				}
+			}
+		}
+	}
+}
+
+func TestColorParallelTooFewColors(t *testing.T) {
+	g := CompleteGraph(5, 0)
+	a, err := g.ColorParallel(4, 2, 1)
+	if err == nil {
+		t.Fatal("Expected coloring K5 with 4 colors to fail")
+	}
+	if ok, used, _ := g.ColoredWith(a, 5); !ok || used != 5 {
+		t.Fatalf("Expected the proper 5-coloring with the error, got %v with %d colors", ok, used)
+	}
+}
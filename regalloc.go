@@ -0,0 +1,598 @@
+// Register allocation for a tiny three-address code language
+
+// Liveness analysis finds the variables live after every instruction. A variable interferes with
+// everything live where it is assigned, except the source of a copy, since both can share a register.
+// Nodes of the interference graph with fewer than k neighbours can always be colored, so Chaitin's
+// simplify step removes them one by one. When only nodes with k or more neighbours remain, the
+// cheapest one per neighbour is spilled to memory: it is loaded into a fresh temporary before every use
+// and stored from one after every definition, and the analysis runs again. Once nothing needs
+// spilling, the select step puts the nodes back in reverse order, each taking the lowest of the
+// k registers its neighbours don't use
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Kinds of three-address code instructions
+type InstrKind int
+
+const (
+	LabelInstr  InstrKind = iota // L:
+	AssignInstr                  // x = a, x = a op b
+	GotoInstr                    // goto L
+	BranchInstr                  // if a op b goto L
+	ReturnInstr                  // return a
+	LoadInstr                    // x = load [v], added for spills
+	StoreInstr                   // store [v], x, added for spills
+)
+
+// Instruction of the three-address code
+type Instr struct {
+	Kind  InstrKind
+	Dest  string   // Assigned variable
+	Args  []string // Operands, variables or integer constants
+	Op    string   // Arithmetic or comparison operator
+	Label string   // Label defined or jumped to
+	Slot  string   // Memory slot of a spilled variable
+}
+
+// Sequence of instructions
+type Program []Instr
+
+// Result of register allocation
+type Allocation struct {
+	Program      Program             // Code after spilling
+	Registers    map[string]string   // Register of each variable
+	Spilled      []string            // Variables kept in memory
+	Interference map[string][]string // Interfering variables of each variable
+}
+
+var arithmeticOps = map[string]bool{"+": true, "-": true, "*": true, "/": true, "%": true}
+var comparisonOps = map[string]bool{"<": true, "<=": true, ">": true, ">=": true, "==": true, "!=": true}
+
+// Check whether a name is a valid identifier
+func isIdentifier(s string) bool {
+	if len(s) == 0 {
+		return false
+	}
+	for i, r := range s {
+		letter := r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+		if !letter && (i == 0 || r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// Operands are variables unless they are integer constants
+func isVariable(s string) bool {
+	_, err := strconv.Atoi(s)
+	return err != nil
+}
+
+// Check that an operand is a variable or an integer constant
+func checkOperand(s string) error {
+	if isVariable(s) && !isIdentifier(s) {
+		return fmt.Errorf("Invalid operand %q", s)
+	}
+	return nil
+}
+
+// Parse a program, one instruction per line with # starting a comment
+func ParseProgram(r io.Reader) (Program, error) {
+	p := make(Program, 0)
+	labels := make(map[string]bool)
+	scanner := bufio.NewScanner(r)
+
+	for line := 1; scanner.Scan(); line++ {
+		text, _, _ := strings.Cut(scanner.Text(), "#")
+		f := strings.Fields(text)
+		if len(f) == 0 {
+			continue
+		}
+
+		var in Instr
+		switch {
+		case len(f) == 1 && strings.HasSuffix(f[0], ":"):
+			in = Instr{Kind: LabelInstr, Label: strings.TrimSuffix(f[0], ":")}
+			if !isIdentifier(in.Label) {
+				return nil, fmt.Errorf("Line %d: invalid label %q", line, in.Label)
+			}
+			if labels[in.Label] {
+				return nil, fmt.Errorf("Line %d: label %s defined twice", line, in.Label)
+			}
+			labels[in.Label] = true
+		case len(f) == 2 && f[0] == "goto":
+			in = Instr{Kind: GotoInstr, Label: f[1]}
+		case len(f) == 2 && f[0] == "return":
+			in = Instr{Kind: ReturnInstr, Args: []string{f[1]}}
+		case len(f) == 6 && f[0] == "if" && f[4] == "goto" && comparisonOps[f[2]]:
+			in = Instr{Kind: BranchInstr, Args: []string{f[1], f[3]}, Op: f[2], Label: f[5]}
+		case len(f) == 3 && f[1] == "=":
+			in = Instr{Kind: AssignInstr, Dest: f[0], Args: []string{f[2]}}
+		case len(f) == 5 && f[1] == "=" && arithmeticOps[f[3]]:
+			in = Instr{Kind: AssignInstr, Dest: f[0], Args: []string{f[2], f[4]}, Op: f[3]}
+		default:
+			return nil, fmt.Errorf("Line %d: unrecognized instruction %q", line, strings.TrimSpace(text))
+		}
+
+		if in.Dest != "" && !isIdentifier(in.Dest) {
+			return nil, fmt.Errorf("Line %d: invalid variable %q", line, in.Dest)
+		}
+		for _, a := range in.Args {
+			if err := checkOperand(a); err != nil {
+				return nil, fmt.Errorf("Line %d: %w", line, err)
+			}
+		}
+		p = append(p, in)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, in := range p {
+		if (in.Kind == GotoInstr || in.Kind == BranchInstr) && !labels[in.Label] {
+			return nil, fmt.Errorf("Jump to undefined label %s", in.Label)
+		}
+	}
+	return p, nil
+}
+
+// Format an instruction, naming variables with the given function
+func (in Instr) format(name func(string) string) string {
+	operand := func(s string) string {
+		if isVariable(s) {
+			return name(s)
+		}
+		return s
+	}
+	switch in.Kind {
+	case LabelInstr:
+		return in.Label + ":"
+	case AssignInstr:
+		if len(in.Args) == 1 {
+			return fmt.Sprintf("    %s = %s", name(in.Dest), operand(in.Args[0]))
+		}
+		return fmt.Sprintf("    %s = %s %s %s", name(in.Dest), operand(in.Args[0]), in.Op, operand(in.Args[1]))
+	case GotoInstr:
+		return "    goto " + in.Label
+	case BranchInstr:
+		return fmt.Sprintf("    if %s %s %s goto %s", operand(in.Args[0]), in.Op, operand(in.Args[1]), in.Label)
+	case ReturnInstr:
+		return "    return " + operand(in.Args[0])
+	case LoadInstr:
+		return fmt.Sprintf("    %s = load [%s]", name(in.Dest), in.Slot)
+	case StoreInstr:
+		return fmt.Sprintf("    store [%s], %s", in.Slot, name(in.Args[0]))
+	}
+	return ""
+}
+
+// Format an instruction to string
+func (in Instr) String() string {
+	return in.format(func(s string) string { return s })
+}
+
+// Format a program to string, one instruction per line
+func (p Program) String() string {
+	lines := make([]string, len(p))
+	for i, in := range p {
+		lines[i] = in.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Variables read by the instruction
+func (in Instr) uses() []string {
+	vars := make([]string, 0, len(in.Args))
+	for _, a := range in.Args {
+		if isVariable(a) {
+			vars = append(vars, a)
+		}
+	}
+	return vars
+}
+
+// Variables in order of first appearance
+func (p Program) Variables() []string {
+	seen := make(map[string]bool)
+	vars := make([]string, 0)
+	for _, in := range p {
+		for _, v := range append(in.uses(), in.Dest) {
+			if v != "" && !seen[v] {
+				seen[v] = true
+				vars = append(vars, v)
+			}
+		}
+	}
+	return vars
+}
+
+// Instructions that can run after each instruction
+func (p Program) successors() [][]int {
+	labels := make(map[string]int)
+	for i, in := range p {
+		if in.Kind == LabelInstr {
+			labels[in.Label] = i
+		}
+	}
+	succ := make([][]int, len(p))
+	for i, in := range p {
+		switch in.Kind {
+		case GotoInstr:
+			succ[i] = []int{labels[in.Label]}
+		case BranchInstr:
+			succ[i] = []int{labels[in.Label]}
+			if i+1 < len(p) {
+				succ[i] = append(succ[i], i+1)
+			}
+		case ReturnInstr:
+		default:
+			if i+1 < len(p) {
+				succ[i] = []int{i + 1}
+			}
+		}
+	}
+	return succ
+}
+
+// Variables live after each instruction, iterated backwards until nothing changes
+func (p Program) Liveness() []map[string]bool {
+	succ := p.successors()
+	liveIn := make([]map[string]bool, len(p))
+	liveOut := make([]map[string]bool, len(p))
+	for i := range p {
+		liveIn[i] = make(map[string]bool)
+		liveOut[i] = make(map[string]bool)
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for i := len(p) - 1; i >= 0; i-- {
+			for _, s := range succ[i] {
+				for v := range liveIn[s] {
+					if !liveOut[i][v] {
+						liveOut[i][v] = true
+						changed = true
+					}
+				}
+			}
+
+			// Live in: used here, or live out and not assigned here
+			for v := range liveOut[i] {
+				if v != p[i].Dest && !liveIn[i][v] {
+					liveIn[i][v] = true
+					changed = true
+				}
+			}
+			for _, v := range p[i].uses() {
+				if !liveIn[i][v] {
+					liveIn[i][v] = true
+					changed = true
+				}
+			}
+		}
+	}
+	return liveOut
+}
+
+// Sorted names of a set of variables
+func sortedNames(set map[string]bool) []string {
+	names := make([]string, 0, len(set))
+	for v := range set {
+		names = append(names, v)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Build the interference graph, each node holds its variable name
+func (p Program) InterferenceGraph() (*Graph[string], map[string]*Node[string]) {
+	g := EmptyGraph[string]()
+	nodes := make(map[string]*Node[string])
+	for _, v := range p.Variables() {
+		nodes[v] = g.AddNodes(1, v)[0]
+	}
+
+	for i, live := range p.Liveness() {
+		in := p[i]
+		if in.Dest == "" {
+			continue
+		}
+		for _, v := range sortedNames(live) {
+			// The source of a copy holds the same value, so it doesn't interfere
+			isCopy := in.Kind == AssignInstr && len(in.Args) == 1 && in.Args[0] == v
+			if v != in.Dest && !isCopy {
+				g.Connect(Edge[string]{nodes[in.Dest], nodes[v]})
+			}
+		}
+	}
+	return g, nodes
+}
+
+// Spill cost of each variable, the number of times it is read or assigned
+func (p Program) spillCosts(temps map[string]bool) map[string]float64 {
+	costs := make(map[string]float64)
+	for _, in := range p {
+		for _, v := range append(in.uses(), in.Dest) {
+			if v != "" {
+				costs[v]++
+			}
+		}
+	}
+
+	// Spilling a temporary wouldn't shorten its live range
+	for v := range temps {
+		costs[v] = math.Inf(1)
+	}
+	return costs
+}
+
+// Simplify the interference graph, returns the variables in the order they were removed
+// and the variables to spill
+func chaitinSimplify(g *Graph[string], k int, costs map[string]float64) ([]string, []string, error) {
+	work, _ := g.Clone()
+	stack := make([]string, 0, len(g.nodes))
+	spills := make([]string, 0)
+
+	for len(work.nodes) > 0 {
+		var next *Node[string]
+
+		// Nodes with fewer than k neighbours will always find a register
+		for _, n := range work.Nodes() {
+			if len(n.neighbours) < k {
+				next = n
+				break
+			}
+		}
+
+		// Otherwise spill the node with the lowest cost per neighbour
+		if next == nil {
+			best := math.Inf(1)
+			for _, n := range work.Nodes() {
+				if c := costs[n.value] / float64(len(n.neighbours)); c < best {
+					best, next = c, n
+				}
+			}
+			if next == nil {
+				return nil, nil, fmt.Errorf("Not enough registers, the spill temporaries alone need more than %d", k)
+			}
+			spills = append(spills, next.value)
+		} else {
+			stack = append(stack, next.value)
+		}
+		work.RemoveNode(next)
+	}
+	return stack, spills, nil
+}
+
+// Chaitin's select step, pop the simplify stack and give each variable the lowest register
+// its already colored neighbours don't use. Every variable had fewer than k neighbours
+// when it was removed, so one of the k registers is always free.
+// Color isn't used here since it only colors connected graphs, and interference graphs are
+// often disconnected, e.g. when a variable's live range overlaps no other
+func chaitinSelect(nodes map[string]*Node[string], stack []string, k int) (map[string]int, error) {
+	colors := make(map[string]int, len(stack))
+	for i := len(stack) - 1; i >= 0; i-- {
+		n := nodes[stack[i]]
+		taken := make([]bool, k)
+		for e := range n.neighbours {
+			if c, ok := colors[e.Other(n).value]; ok {
+				taken[c] = true
+			}
+		}
+		colors[n.value] = -1
+		for c := range taken {
+			if !taken[c] {
+				colors[n.value] = c
+				break
+			}
+		}
+		if colors[n.value] < 0 {
+			return nil, fmt.Errorf("No register left for %s", n.value)
+		}
+	}
+	return colors, nil
+}
+
+// Rewrite the program so the spilled variables live in memory
+// Every instruction touching a spilled variable gets its own temporary, which are added to temps
+func (p Program) spill(spills []string, temps map[string]bool) Program {
+	spilled := make(map[string]bool)
+	for _, v := range spills {
+		spilled[v] = true
+	}
+
+	rewritten := make(Program, 0, len(p))
+	count := make(map[string]int)
+	for _, in := range p {
+		rename := make(map[string]string)
+		temp := func(v string) string {
+			if _, ok := rename[v]; !ok {
+				count[v]++
+				rename[v] = fmt.Sprintf("%s.%d", v, count[v])
+				temps[rename[v]] = true
+			}
+			return rename[v]
+		}
+
+		args := append([]string(nil), in.Args...)
+		for i, a := range args {
+			if isVariable(a) && spilled[a] {
+				if _, loaded := rename[a]; !loaded {
+					rewritten = append(rewritten, Instr{Kind: LoadInstr, Dest: temp(a), Slot: a})
+				}
+				args[i] = rename[a]
+			}
+		}
+		in.Args = args
+
+		if slot := in.Dest; spilled[slot] {
+			in.Dest = temp(slot)
+			rewritten = append(rewritten, in, Instr{Kind: StoreInstr, Args: []string{in.Dest}, Slot: slot})
+		} else {
+			rewritten = append(rewritten, in)
+		}
+	}
+	return rewritten
+}
+
+// Allocate k registers to the variables of the program, spilling variables to memory as needed
+func (p Program) Allocate(k int) (*Allocation, error) {
+	if k < 1 {
+		return nil, errors.New("Need at least one register")
+	}
+
+	spilled := make([]string, 0)
+	temps := make(map[string]bool)
+	for {
+		g, nodes := p.InterferenceGraph()
+		stack, spills, err := chaitinSimplify(g, k, p.spillCosts(temps))
+		if err != nil {
+			return nil, err
+		}
+
+		if len(spills) > 0 {
+			spilled = append(spilled, spills...)
+			p = p.spill(spills, temps)
+			continue
+		}
+
+		interference := make(map[string][]string)
+		for v, n := range nodes {
+			neighbours := make(map[string]bool)
+			for e := range n.neighbours {
+				neighbours[e.Other(n).value] = true
+			}
+			interference[v] = sortedNames(neighbours)
+		}
+
+		// Simplify succeeded, so selecting registers in reverse order can't fail
+		colors, err := chaitinSelect(nodes, stack, k)
+		if err != nil {
+			return nil, err
+		}
+
+		allocation := &Allocation{p, make(map[string]string), spilled, interference}
+		for v, c := range colors {
+			allocation.Registers[v] = fmt.Sprintf("r%d", c)
+		}
+		return allocation, nil
+	}
+}
+
+// Format the allocated code, copies between the same register are dropped
+func (a *Allocation) Code() string {
+	lines := make([]string, 0, len(a.Program))
+	for _, in := range a.Program {
+		if in.Kind == AssignInstr && len(in.Args) == 1 && a.Registers[in.Dest] == a.Registers[in.Args[0]] {
+			continue
+		}
+		lines = append(lines, in.format(func(v string) string { return a.Registers[v] }))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Example program for when no input file is given
+const exampleProgram = `# Sum of squares and cubes up to n
+n = 10
+i = 0
+s = 0
+c = 0
+loop:
+if i >= n goto done
+t = i * i
+s = s + t
+u = t * i
+c = c + u
+i = i + 1
+goto loop
+done:
+r = s + c
+d = r
+return d
+`
+
+// Program to demonstrate register allocation with graph coloring
+func RunRegisterAllocation() {
+	var K int        // Number of registers
+	var In string    // Input program file
+	var noPrint bool // Do not print the liveness analysis
+
+	// Extract args
+	for i, v := range os.Args {
+		switch v {
+		case "-K", "--K":
+			SScanInt(ArgValue(i), &K, "K")
+		case "-I", "--I":
+			fmt.Sscanf(ArgValue(i), "%s", &In)
+		case "--noprint":
+			noPrint = true
+		}
+	}
+
+	fmt.Println("---- Register allocation program ----")
+
+	var source io.Reader = strings.NewReader(exampleProgram)
+	if len(In) > 0 {
+		file, err := os.Open(In)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		defer file.Close()
+		source = file
+	} else {
+		fmt.Println("No input file given, using the example program")
+	}
+
+	program, err := ParseProgram(source)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	if K == 0 {
+		fmt.Print("Input the number of registers K: ")
+		ScanInt(&K, "K")
+	}
+
+	fmt.Printf("---- Program with %d variables ----\n", len(program.Variables()))
+	if noPrint {
+		fmt.Println(program)
+	} else {
+		for i, live := range program.Liveness() {
+			fmt.Printf("%-28s live: %s\n", program[i], strings.Join(sortedNames(live), " "))
+		}
+	}
+
+	allocation, err := program.Allocate(K)
+	if err != nil {
+		fmt.Println("Unable to allocate registers:", err)
+		os.Exit(1)
+	}
+
+	fmt.Println("---- Interference ----")
+	for _, v := range allocation.Program.Variables() {
+		fmt.Printf("%s (%s): %s\n", v, allocation.Registers[v], strings.Join(allocation.Interference[v], " "))
+	}
+
+	if len(allocation.Spilled) > 0 {
+		fmt.Println("Spilled to memory:", strings.Join(allocation.Spilled, " "))
+	} else {
+		fmt.Println("No spills needed")
+	}
+
+	fmt.Printf("---- Allocated code with %d registers ----\n", K)
+	fmt.Println(allocation.Code())
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// Random program over a few variables with jumps between labels, ending in a return
+func randomProgram(r *rand.Rand) Program {
+	vars := []string{"a", "b", "c", "d", "e", "f", "g", "h"}[:3+r.Intn(6)]
+	labels := 1 + r.Intn(3)
+	operand := func() string {
+		if r.Intn(4) == 0 {
+			return fmt.Sprint(r.Intn(10))
+		}
+		return vars[r.Intn(len(vars))]
+	}
+
+	lines := make([]string, 0)
+	for _, v := range vars {
+		lines = append(lines, fmt.Sprintf("%s = %d", v, r.Intn(10)))
+	}
+	for l := 0; l < labels; l++ {
+		lines = append(lines, fmt.Sprintf("L%d:", l))
+		for i := 0; i < 2+r.Intn(6); i++ {
+			switch r.Intn(5) {
+			case 0:
+				lines = append(lines, fmt.Sprintf("%s = %s", vars[r.Intn(len(vars))], operand()))
+			case 1:
+				lines = append(lines, fmt.Sprintf("if %s < %s goto L%d", operand(), operand(), r.Intn(labels)))
+			default:
+				lines = append(lines, fmt.Sprintf("%s = %s + %s", vars[r.Intn(len(vars))], operand(), operand()))
+			}
+		}
+	}
+	lines = append(lines, "return "+vars[r.Intn(len(vars))])
+
+	p, err := ParseProgram(strings.NewReader(strings.Join(lines, "\n")))
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// Check that every variable has one of the k registers and interfering variables never share one
+func checkAllocation(t *testing.T, a *Allocation, k int) {
+	t.Helper()
+	registers := make(map[string]bool)
+	for i := 0; i < k; i++ {
+		registers[fmt.Sprintf("r%d", i)] = true
+	}
+
+	g, nodes := a.Program.InterferenceGraph()
+	for v := range nodes {
+		if !registers[a.Registers[v]] {
+			t.Fatalf("Variable %s has register %q, expected one of %d", v, a.Registers[v], k)
+		}
+	}
+	for e := range g.edges {
+		if a.Registers[e.a.value] == a.Registers[e.b.value] {
+			t.Fatalf("Interfering variables %s and %s share register %s\n%s", e.a.value, e.b.value, a.Registers[e.a.value], a.Program)
+		}
+	}
+}
+
+func TestAllocateExample(t *testing.T) {
+	p, err := ParseProgram(strings.NewReader(exampleProgram))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k := 2; k <= 6; k++ {
+		a, err := p.Allocate(k)
+		if err != nil {
+			t.Fatalf("Allocating %d registers failed: %v", k, err)
+		}
+		checkAllocation(t, a, k)
+	}
+}
+
+func TestAllocateRandomPrograms(t *testing.T) {
+	for seed := int64(1); seed <= 500; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		p := randomProgram(r)
+		for k := 2; k <= 6; k++ {
+			a, err := p.Allocate(k)
+			if err != nil {
+				t.Fatalf("Seed %d: allocating %d registers failed: %v\n%s", seed, k, err, p)
+			}
+			checkAllocation(t, a, k)
+		}
+	}
+}
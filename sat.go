@@ -0,0 +1,451 @@
+// A small CDCL SAT solver and DIMACS CNF reading and writing
+
+// The solver propagates with two watched literals per clause, learns a clause from every
+// conflict by resolving back to the first unique implication point, backjumps to the second
+// highest level of the learned clause, picks decisions by variable activity and restarts
+// on the Luby sequence. Learned clauses are never deleted, so it is meant for small instances
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Boolean formula in conjunctive normal form
+// Variables are numbered from 1, and a negative literal is the negation of its variable as in DIMACS
+type CNF struct {
+	Vars    int
+	Clauses [][]int
+}
+
+// Create a new variable
+func (f *CNF) NewVar() int {
+	f.Vars++
+	return f.Vars
+}
+
+// Add a clause, the disjunction of the literals
+func (f *CNF) Add(clause ...int) {
+	f.Clauses = append(f.Clauses, clause)
+}
+
+// Write the formula in DIMACS CNF format
+func (f *CNF) WriteDIMACS(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "p cnf %d %d\n", f.Vars, len(f.Clauses))
+	for _, clause := range f.Clauses {
+		for _, lit := range clause {
+			fmt.Fprintf(bw, "%d ", lit)
+		}
+		fmt.Fprintln(bw, "0")
+	}
+	return bw.Flush()
+}
+
+// Read a formula in DIMACS CNF format
+func ReadDIMACS(r io.Reader) (*CNF, error) {
+	f := &CNF{}
+	clause := make([]int, 0)
+	header := false
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || line[0] == 'c' {
+			continue
+		}
+		// Some benchmark files end with a % line followed by a stray 0
+		if line[0] == '%' {
+			break
+		}
+		if line[0] == 'p' {
+			var clauses int
+			if _, err := fmt.Sscanf(line, "p cnf %d %d", &f.Vars, &clauses); err != nil || f.Vars < 0 || clauses < 0 {
+				return nil, errors.New("Invalid DIMACS header")
+			}
+			header = true
+			continue
+		}
+		if !header {
+			return nil, errors.New("Missing DIMACS header")
+		}
+		for _, field := range strings.Fields(line) {
+			lit, err := strconv.Atoi(field)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid literal %q", field)
+			}
+			if lit == 0 {
+				f.Add(clause...)
+				clause = make([]int, 0)
+				continue
+			}
+			if lit > f.Vars || -lit > f.Vars {
+				return nil, fmt.Errorf("Literal %d is out of range", lit)
+			}
+			clause = append(clause, lit)
+		}
+	}
+	if len(clause) > 0 {
+		f.Add(clause...)
+	}
+	return f, scanner.Err()
+}
+
+// Check whether the model satisfies every clause
+func (f *CNF) Satisfied(model []bool) bool {
+	for _, clause := range f.Clauses {
+		ok := false
+		for _, lit := range clause {
+			if (lit > 0) == model[abs(lit)] {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Absolute value of x
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// Solve the formula, returns whether it is satisfiable and a model indexed by variable
+func (f *CNF) Solve() (bool, []bool) {
+	sat, model, _ := f.SolveContext(context.Background())
+	return sat, model
+}
+
+// Solve the formula, stopping with ErrTimeout when the context is done
+func (f *CNF) SolveContext(ctx context.Context) (bool, []bool, error) {
+	s := newSolver(f.Vars)
+	for _, clause := range f.Clauses {
+		if !s.addClause(clause) {
+			return false, nil, nil
+		}
+	}
+	return s.solve(ctx)
+}
+
+// Internally literals are 2*v for variable v and 2*v+1 for its negation, with v from 0
+type solver struct {
+	vars     int
+	clauses  [][]int
+	watches  [][]int   // Clauses watching each literal
+	assign   []int8    // 1 true, -1 false, 0 unassigned
+	level    []int     // Decision level of each assigned variable
+	reason   []int     // Clause that implied each variable, -1 for decisions
+	trail    []int     // Assigned literals in order
+	limits   []int     // Trail length at the start of each decision level
+	head     int       // Next trail position to propagate
+	activity []float64 // Variable activity for decisions
+	bump     float64
+	phase    []bool // Last assigned value of each variable
+}
+
+func newSolver(vars int) *solver {
+	s := &solver{
+		vars:     vars,
+		watches:  make([][]int, 2*vars),
+		assign:   make([]int8, vars),
+		level:    make([]int, vars),
+		reason:   make([]int, vars),
+		activity: make([]float64, vars),
+		bump:     1,
+		phase:    make([]bool, vars),
+	}
+	return s
+}
+
+// Convert a DIMACS literal to the internal form
+func encodeLit(lit int) int {
+	if lit > 0 {
+		return 2 * (lit - 1)
+	}
+	return 2*(-lit-1) + 1
+}
+
+// Value of a literal, 1 true, -1 false, 0 unassigned
+func (s *solver) value(lit int) int8 {
+	v := s.assign[lit/2]
+	if lit%2 == 1 {
+		return -v
+	}
+	return v
+}
+
+func (s *solver) decisionLevel() int {
+	return len(s.limits)
+}
+
+// Assign a literal true
+func (s *solver) enqueue(lit int, reason int) {
+	v := lit / 2
+	if lit%2 == 0 {
+		s.assign[v] = 1
+	} else {
+		s.assign[v] = -1
+	}
+	s.level[v] = s.decisionLevel()
+	s.reason[v] = reason
+	s.phase[v] = lit%2 == 0
+	s.trail = append(s.trail, lit)
+}
+
+// Add an original clause at level 0, returns false if the formula is already unsatisfiable
+func (s *solver) addClause(clause []int) bool {
+	lits := make([]int, 0, len(clause))
+	seen := make(map[int]bool)
+	for _, l := range clause {
+		lit := encodeLit(l)
+		if seen[lit^1] {
+			return true // Always satisfied
+		}
+		if !seen[lit] {
+			seen[lit] = true
+			lits = append(lits, lit)
+		}
+	}
+
+	switch len(lits) {
+	case 0:
+		return false
+	case 1:
+		switch s.value(lits[0]) {
+		case -1:
+			return false
+		case 0:
+			s.enqueue(lits[0], -1)
+		}
+		return true
+	}
+	s.attach(lits)
+	return true
+}
+
+// Store a clause and watch its first two literals
+func (s *solver) attach(lits []int) int {
+	id := len(s.clauses)
+	s.clauses = append(s.clauses, lits)
+	s.watches[lits[0]] = append(s.watches[lits[0]], id)
+	s.watches[lits[1]] = append(s.watches[lits[1]], id)
+	return id
+}
+
+// Propagate the assignments on the trail, returns a conflicting clause or -1
+func (s *solver) propagate() int {
+	for s.head < len(s.trail) {
+		falseLit := s.trail[s.head] ^ 1
+		s.head++
+
+		watching := s.watches[falseLit]
+		kept := watching[:0]
+		conflict := -1
+
+		for w, id := range watching {
+			if conflict >= 0 {
+				kept = append(kept, watching[w:]...)
+				break
+			}
+			c := s.clauses[id]
+			if c[0] == falseLit {
+				c[0], c[1] = c[1], c[0]
+			}
+
+			// Satisfied by the other watch
+			if s.value(c[0]) == 1 {
+				kept = append(kept, id)
+				continue
+			}
+
+			// Look for a new literal to watch
+			moved := false
+			for k := 2; k < len(c); k++ {
+				if s.value(c[k]) != -1 {
+					c[1], c[k] = c[k], c[1]
+					s.watches[c[1]] = append(s.watches[c[1]], id)
+					moved = true
+					break
+				}
+			}
+			if moved {
+				continue
+			}
+
+			kept = append(kept, id)
+			if s.value(c[0]) == -1 {
+				conflict = id
+			} else {
+				s.enqueue(c[0], id)
+			}
+		}
+		s.watches[falseLit] = kept
+
+		if conflict >= 0 {
+			return conflict
+		}
+	}
+	return -1
+}
+
+// Learn a clause from the conflict, returns it with the asserting literal first and the level to backjump to
+func (s *solver) analyze(conflict int) ([]int, int) {
+	seen := make([]bool, s.vars)
+	learned := []int{-1}
+	counter := 0
+	lit := -1
+	index := len(s.trail) - 1
+
+	for {
+		for _, q := range s.clauses[conflict] {
+			if q == lit {
+				continue
+			}
+			v := q / 2
+			if seen[v] || s.level[v] == 0 {
+				continue
+			}
+			seen[v] = true
+			s.bumpVar(v)
+			if s.level[v] == s.decisionLevel() {
+				counter++
+			} else {
+				learned = append(learned, q)
+			}
+		}
+
+		// Next literal of the current level on the trail
+		for !seen[s.trail[index]/2] {
+			index--
+		}
+		lit = s.trail[index]
+		conflict = s.reason[lit/2]
+		seen[lit/2] = false
+		index--
+		counter--
+		if counter == 0 {
+			break
+		}
+	}
+	learned[0] = lit ^ 1
+
+	// Backjump to the highest level among the other literals, moved to the second watch
+	backjump := 0
+	for i := 1; i < len(learned); i++ {
+		if l := s.level[learned[i]/2]; l > backjump {
+			backjump = l
+			learned[1], learned[i] = learned[i], learned[1]
+		}
+	}
+	s.bump /= 0.95
+	return learned, backjump
+}
+
+// Increase the activity of a variable involved in a conflict
+func (s *solver) bumpVar(v int) {
+	s.activity[v] += s.bump
+	if s.activity[v] > 1e100 {
+		for i := range s.activity {
+			s.activity[i] *= 1e-100
+		}
+		s.bump *= 1e-100
+	}
+}
+
+// Undo assignments above the level
+func (s *solver) backtrack(level int) {
+	if s.decisionLevel() <= level {
+		return
+	}
+	for i := len(s.trail) - 1; i >= s.limits[level]; i-- {
+		s.assign[s.trail[i]/2] = 0
+	}
+	s.trail = s.trail[:s.limits[level]]
+	s.limits = s.limits[:level]
+	s.head = len(s.trail)
+}
+
+// Unassigned variable with the highest activity, -1 if all are assigned
+func (s *solver) pickBranch() int {
+	best := -1
+	for v := 0; v < s.vars; v++ {
+		if s.assign[v] == 0 && (best < 0 || s.activity[v] > s.activity[best]) {
+			best = v
+		}
+	}
+	return best
+}
+
+// The Luby sequence 1 1 2 1 1 2 4 1 1 2 ... for restart intervals
+func luby(i int) int {
+	for k := 1; ; k++ {
+		if i == (1<<k)-1 {
+			return 1 << (k - 1)
+		}
+		if i >= 1<<(k-1) && i < (1<<k)-1 {
+			return luby(i - (1 << (k - 1)) + 1)
+		}
+	}
+}
+
+func (s *solver) solve(ctx context.Context) (bool, []bool, error) {
+	restarts, conflicts := 1, 0
+	limit := 100 * luby(restarts)
+
+	for {
+		conflict := s.propagate()
+		if conflict >= 0 {
+			if s.decisionLevel() == 0 {
+				return false, nil, nil
+			}
+			conflicts++
+			if conflicts%contextCheckInterval == 0 && ctx.Err() != nil {
+				return false, nil, timeoutError(ctx)
+			}
+
+			learned, backjump := s.analyze(conflict)
+			s.backtrack(backjump)
+			if len(learned) == 1 {
+				s.enqueue(learned[0], -1)
+			} else {
+				s.enqueue(learned[0], s.attach(learned))
+			}
+			continue
+		}
+
+		// Restart, keeping the learned clauses
+		if conflicts >= limit {
+			s.backtrack(0)
+			restarts++
+			conflicts = 0
+			limit = 100 * luby(restarts)
+		}
+
+		v := s.pickBranch()
+		if v < 0 {
+			model := make([]bool, s.vars+1)
+			for i := range s.assign {
+				model[i+1] = s.assign[i] == 1
+			}
+			return true, model, nil
+		}
+
+		s.limits = append(s.limits, len(s.trail))
+		if s.phase[v] {
+			s.enqueue(2*v, -1)
+		} else {
+			s.enqueue(2*v+1, -1)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Random CNF with clauses of one to three literals
+func randomCNF(r *rand.Rand, vars, clauses int) *CNF {
+	f := &CNF{Vars: vars}
+	for i := 0; i < clauses; i++ {
+		clause := make([]int, 1+r.Intn(3))
+		for j := range clause {
+			clause[j] = 1 + r.Intn(vars)
+			if r.Intn(2) == 0 {
+				clause[j] = -clause[j]
+			}
+		}
+		f.Add(clause...)
+	}
+	return f
+}
+
+// Decide satisfiability by trying every assignment
+func bruteForceSAT(f *CNF) bool {
+	model := make([]bool, f.Vars+1)
+	for bits := 0; bits < 1<<f.Vars; bits++ {
+		for v := 1; v <= f.Vars; v++ {
+			model[v] = bits&(1<<(v-1)) != 0
+		}
+		if f.Satisfied(model) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSolveMatchesBruteForce(t *testing.T) {
+	r := rand.New(rand.NewSource(12))
+	counts := make(map[bool]int)
+	for round := 0; round < 3000; round++ {
+		vars := 1 + r.Intn(10)
+		// Around 4.3 clauses per variable random 3-SAT turns from satisfiable to unsatisfiable
+		f := randomCNF(r, vars, 1+r.Intn(6*vars))
+		expected := bruteForceSAT(f)
+		sat, model := f.Solve()
+		if sat != expected {
+			t.Fatalf("Round %d: solver says %v, brute force %v\n%v", round, sat, expected, f.Clauses)
+		}
+		if sat && (len(model) != vars+1 || !f.Satisfied(model)) {
+			t.Fatalf("Round %d: model %v doesn't satisfy %v", round, model, f.Clauses)
+		}
+		counts[sat]++
+	}
+	if counts[true] < 100 || counts[false] < 100 {
+		t.Errorf("Too few instances of one kind: %v", counts)
+	}
+}
+
+func TestSolveEdgeCases(t *testing.T) {
+	if sat, _ := (&CNF{}).Solve(); !sat {
+		t.Error("Empty formula should be satisfiable")
+	}
+	f := &CNF{Vars: 2}
+	f.Add(1, 2)
+	f.Add()
+	if sat, _ := f.Solve(); sat {
+		t.Error("Formula with an empty clause should be unsatisfiable")
+	}
+	f = &CNF{Vars: 1}
+	f.Add(1)
+	f.Add(-1)
+	if sat, _ := f.Solve(); sat {
+		t.Error("x and not x should be unsatisfiable")
+	}
+}
+
+func TestDIMACSRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(13))
+	for round := 0; round < 200; round++ {
+		f := randomCNF(r, 1+r.Intn(20), r.Intn(40))
+		var buf bytes.Buffer
+		if err := f.WriteDIMACS(&buf); err != nil {
+			t.Fatal(err)
+		}
+		read, err := ReadDIMACS(&buf)
+		if err != nil {
+			t.Fatalf("Round %d: %v", round, err)
+		}
+		if read.Vars != f.Vars || !reflect.DeepEqual(read.Clauses, f.Clauses) {
+			t.Fatalf("Round %d: read %d vars %v, wrote %d vars %v", round, read.Vars, read.Clauses, f.Vars, f.Clauses)
+		}
+	}
+}
+
+func TestReadDIMACSComments(t *testing.T) {
+	f, err := ReadDIMACS(strings.NewReader("c comment\np cnf 3 2\n1 -2\n0 3\n-1 0\n%\n0\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Clauses can span lines and several can share one
+	if f.Vars != 3 || !reflect.DeepEqual(f.Clauses, [][]int{{1, -2}, {3, -1}}) {
+		t.Errorf("Read %d vars %v", f.Vars, f.Clauses)
+	}
+}
+
+func TestReadDIMACSErrors(t *testing.T) {
+	for _, input := range []string{
+		"1 2 0\n",
+		"p cnf\n1 0\n",
+		"p cnf 3\n1 0\n",
+		"p cnf x 2\n",
+		"p cnf -1 2\n",
+		"p cnf 3 -2\n",
+		"p dnf 3 2\n",
+		"p cnf 3 1\n1 x 0\n",
+		"p cnf 3 1\n1 4 0\n",
+		"p cnf 3 1\n-4 0\n",
+	} {
+		if _, err := ReadDIMACS(strings.NewReader(input)); err == nil {
+			t.Errorf("Expected an error for %q", input)
+		}
+	}
+}
+
+func TestColorSATMatchesCountColorings(t *testing.T) {
+	r := rand.New(rand.NewSource(14))
+	for round := 0; round < 200; round++ {
+		N := 1 + r.Intn(8)
+		g := randomDenseGraph(r, N, r.Float64())
+		for k := 1; k <= N; k++ {
+			a, err := g.ColorSAT(k)
+			colorable := g.CountColorings(k).Sign() > 0
+			if colorable != (err == nil) {
+				t.Fatalf("Round %d: %d colorings with %d colors but SAT returned %v", round, g.CountColorings(k), k, err)
+			}
+			if err == nil {
+				if ok, _, _ := g.ColoredWith(a, k); !ok {
+					t.Fatalf("Round %d: improper SAT coloring with %d colors", round, k)
+				}
+			}
+		}
+	}
+}
@@ -1,12 +1,13 @@
 package main
 
 import (
+	"bytes"
 	_ "encoding/json"
-	"errors"
 	"fmt"
 	"image"
 	_ "image/jpeg"
 	_ "image/png"
+	"io"
 	"math/rand"
 	"os"
 	"sync"
@@ -190,8 +191,31 @@ func ReadInputFile(path string) (int, []bool, error) {
 		return 0, nil, err
 	}
 	defer file.Close()
+	return DecodeInputImage(file)
+}
+
+// Largest side of an input image, checked before decoding so a small file can't claim a huge image
+const maxInputSide = 8192
+
+// Decode a square png or jpeg image into a matrix of dark cells
+func DecodeInputImage(r io.Reader) (int, []bool, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	config, format, err := image.DecodeConfig(bytes.NewReader(raw))
+	if err != nil {
+		return 0, nil, err
+	}
+	if config.Width != config.Height {
+		return 0, nil, fmt.Errorf("Image must be square, found: %v x %v", config.Width, config.Height)
+	}
+	if config.Width <= 0 || config.Width > maxInputSide {
+		return 0, nil, fmt.Errorf("Image side must be between 1 and %d, found: %v", maxInputSide, config.Width)
+	}
 
-	img, format, err := image.Decode(file)
+	img, _, err := image.Decode(bytes.NewReader(raw))
 	if err != nil {
 		return 0, nil, err
 	}
@@ -199,8 +223,8 @@ func ReadInputFile(path string) (int, []bool, error) {
 	fmt.Println("Successfully read", format, "file")
 	W, H := img.Bounds().Dx(), img.Bounds().Dy()
 
-	if W != H {
-		return 0, nil, errors.New(fmt.Sprintf("Image must be square, found: %v x %v", W, H))
+	if W != H || W != config.Width {
+		return 0, nil, fmt.Errorf("Image size %v x %v doesn't match its header", W, H)
 	}
 
 	L := W * H
@@ -245,15 +269,15 @@ func RunSubmatrix() {
 	for i, v := range os.Args {
 		switch v {
 		case "-N", "--N":
-			SScanInt(os.Args[i+1], &N, "N")
+			SScanInt(ArgValue(i), &N, "N")
 		case "-B", "--B":
-			SScanInt(os.Args[i+1], &B, "B")
+			SScanInt(ArgValue(i), &B, "B")
 		case "-S", "--S":
-			SScanInt(os.Args[i+1], &intSeed, "seed")
+			SScanInt(ArgValue(i), &intSeed, "seed")
 		case "-O", "--O":
-			fmt.Sscanf(os.Args[i+1], "%s", &Out)
+			fmt.Sscanf(ArgValue(i), "%s", &Out)
 		case "-I", "--I":
-			fmt.Sscanf(os.Args[i+1], "%s", &In)
+			fmt.Sscanf(ArgValue(i), "%s", &In)
 		case "--noprint":
 			noPrint = true
 		case "--novisuals":
@@ -0,0 +1,281 @@
+// Sudoku and Latin square solving with graph coloring
+
+// Solving a sudoku is 9-coloring the sudoku graph with the given cells locked to their digit.
+// The coloring engine solves the puzzle within a time budget, and the exact SAT coloring
+// finishes the puzzle if it runs out of time
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Example puzzle for when no input file is given
+const examplePuzzle = `
+5 3 . | . 7 . | . . .
+6 . . | 1 9 5 | . . .
+. 9 8 | . . . | . 6 .
+------+-------+------
+8 . . | . 6 . | . . 3
+4 . . | 8 . 3 | . . 1
+7 . . | . 2 . | . . 6
+------+-------+------
+. 6 . | . . . | 2 8 .
+. . . | 4 1 9 | . . 5
+. . . | . 8 . | . 7 9
+`
+
+// Parse a square grid of symbols 1 to n, with 0 or . for empty cells
+// Lines are split into cells by whitespace, or into single characters if there is none,
+// and | + - are ignored so grids can be drawn with boxes
+func ParseGrid(r io.Reader) ([][]int, error) {
+	grid := make([][]int, 0)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.Map(func(r rune) rune {
+			if strings.ContainsRune("|+-", r) {
+				return ' '
+			}
+			return r
+		}, scanner.Text())
+
+		cells := strings.Fields(line)
+		if len(cells) == 0 {
+			continue
+		}
+		if len(cells) == 1 {
+			cells = strings.Split(cells[0], "")
+		}
+
+		row := make([]int, len(cells))
+		for i, c := range cells {
+			if c == "." {
+				continue
+			}
+			v, err := strconv.Atoi(c)
+			if err != nil || v < 0 {
+				return nil, fmt.Errorf("Row %d: invalid cell %q", len(grid)+1, c)
+			}
+			row[i] = v
+		}
+		grid = append(grid, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	n := len(grid)
+	if n == 0 {
+		return nil, errors.New("Grid is empty")
+	}
+	for i, row := range grid {
+		if len(row) != n {
+			return nil, fmt.Errorf("Row %d has %d cells, expected %d", i+1, len(row), n)
+		}
+		for _, v := range row {
+			if v > n {
+				return nil, fmt.Errorf("Row %d: symbol %d is larger than the grid size %d", i+1, v, n)
+			}
+		}
+	}
+	return grid, nil
+}
+
+// Box size of a sudoku grid, 0 if the grid size is not a square
+func boxSize(grid [][]int) int {
+	box := int(math.Round(math.Sqrt(float64(len(grid)))))
+	if box*box != len(grid) {
+		return 0
+	}
+	return box
+}
+
+// Build the graph of a grid, as a sudoku if box > 0 and otherwise as a Latin square
+// Returns the graph, its nodes by row and column, and the given cells as locked colors
+func GridGraph(grid [][]int, box int) (*Graph[int], [][]*Node[int], ColorConstraints[int]) {
+	var g *Graph[int]
+	var cells [][]*Node[int]
+	if box > 0 {
+		g, cells = SudokuGraph(box, 0)
+	} else {
+		g, cells = LatinSquareGraph(len(grid), 0)
+	}
+
+	// Given cells are locked to their symbol
+	constraints := ColorConstraints[int]{Locked: make(map[*Node[int]]int)}
+	for r, row := range grid {
+		for c, v := range row {
+			if v > 0 {
+				constraints.Locked[cells[r][c]] = v - 1
+			}
+		}
+	}
+	return g, cells, constraints
+}
+
+// Fill the empty cells of the grid, as a sudoku if box > 0 and otherwise as a Latin square
+// The coloring engine solves it within the timeout, then SAT coloring completes it if time ran out
+// Returns the solved grid and whether SAT coloring was needed
+func SolveGrid(ctx context.Context, grid [][]int, box int, timeout time.Duration) ([][]int, bool, error) {
+	n := len(grid)
+	g, cells, constraints := GridGraph(grid, box)
+
+	// Row and column of the cell a constraint error is about
+	errorCell := func(err error) (int, int, bool) {
+		var constraintErr *ConstraintError[int]
+		if errors.As(err, &constraintErr) {
+			for r, row := range cells {
+				for c, node := range row {
+					if node == constraintErr.Node {
+						return r, c, true
+					}
+				}
+			}
+		}
+		return 0, 0, false
+	}
+
+	// Givens repeating a symbol can't be solved, no point in searching
+	if err := constraints.validate(g, n); err != nil {
+		if r, c, ok := errorCell(err); ok {
+			return nil, false, fmt.Errorf("Symbol %d at row %d, column %d is repeated in its row, column or box", grid[r][c], r+1, c+1)
+		}
+		return nil, false, err
+	}
+
+	engineCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	assignment, err := g.ColorConstrainedContext(engineCtx, n, constraints)
+	usedSAT := false
+	if errors.Is(err, ErrTimeout) && ctx.Err() == nil {
+		usedSAT = true
+		assignment, err = g.ColorSATContext(ctx, n, constraints)
+	}
+	if r, c, ok := errorCell(err); ok {
+		return nil, usedSAT, fmt.Errorf("Puzzle has no solution, symbol %d at row %d, column %d can't be kept with the other givens", grid[r][c], r+1, c+1)
+	}
+	if err != nil {
+		return nil, usedSAT, err
+	}
+
+	// Check the solution end to end
+	if ok, _, conflicts := g.ColoredWith(assignment, n); !ok {
+		return nil, usedSAT, fmt.Errorf("Solution has conflicts: %v", conflicts)
+	}
+	if err := g.CheckConstraints(assignment, constraints); err != nil {
+		return nil, usedSAT, err
+	}
+
+	solved := make([][]int, n)
+	for r := range solved {
+		solved[r] = make([]int, n)
+		for c := range solved[r] {
+			solved[r][c] = assignment[cells[r][c]] + 1
+		}
+	}
+	return solved, usedSAT, nil
+}
+
+// Format a grid to string, drawing the boxes of a sudoku if box > 0
+func FormatGrid(grid [][]int, box int) string {
+	width := len(strconv.Itoa(len(grid)))
+	var sb strings.Builder
+	for r, row := range grid {
+		if box > 0 && r > 0 && r%box == 0 {
+			parts := make([]string, box)
+			for i := range parts {
+				parts[i] = strings.Repeat("-", box*(width+1)+1)
+			}
+			line := strings.Join(parts, "+")
+			sb.WriteString(line[1:len(line)-1] + "\n")
+		}
+		for c, v := range row {
+			if box > 0 && c > 0 && c%box == 0 {
+				sb.WriteString(" |")
+			}
+			if c > 0 {
+				sb.WriteString(" ")
+			}
+			cell := "."
+			if v > 0 {
+				cell = strconv.Itoa(v)
+			}
+			sb.WriteString(fmt.Sprintf("%*s", width, cell))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// Program to demonstrate solving sudokus and Latin squares with graph coloring
+func RunSudoku() {
+	var In string                  // Input grid file
+	var latin bool                 // Solve as a Latin square even if the size is a square
+	var timeout = 10 * time.Second // Time budget for the coloring engine
+
+	// Extract args
+	for i, v := range os.Args {
+		switch v {
+		case "-I", "--I":
+			fmt.Sscanf(ArgValue(i), "%s", &In)
+		case "--latin":
+			latin = true
+		case "--timeout":
+			SScanDuration(ArgValue(i), &timeout, "timeout")
+		}
+	}
+
+	fmt.Println("---- Sudoku and Latin square program ----")
+
+	var source io.Reader = strings.NewReader(examplePuzzle)
+	if len(In) > 0 {
+		file, err := os.Open(In)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		defer file.Close()
+		source = file
+	} else {
+		fmt.Println("No input file given, using the example puzzle")
+	}
+
+	grid, err := ParseGrid(source)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	box := boxSize(grid)
+	if latin {
+		box = 0
+	}
+	if box > 0 {
+		fmt.Printf("---- Sudoku of size %d with %dx%d boxes ----\n", len(grid), box, box)
+	} else {
+		fmt.Printf("---- Latin square of order %d ----\n", len(grid))
+	}
+	fmt.Print(FormatGrid(grid, box))
+
+	start := time.Now()
+	solved, usedSAT, err := SolveGrid(context.Background(), grid, box, timeout)
+	if usedSAT {
+		fmt.Printf("Coloring engine did not finish within %v, completed with SAT coloring\n", timeout)
+	}
+	if err != nil {
+		fmt.Println("Unable to solve:", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("---- Solved in %v ----\n", time.Since(start).Round(time.Millisecond))
+	fmt.Print(FormatGrid(solved, box))
+}
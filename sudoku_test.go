@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Solution of the example puzzle
+const exampleSolution = `
+534678912
+672195348
+198342567
+859761423
+426853791
+713924856
+961537284
+287419635
+345286179
+`
+
+// Parse a grid or fail the test
+func mustParseGrid(t *testing.T, s string) [][]int {
+	t.Helper()
+	grid, err := ParseGrid(strings.NewReader(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return grid
+}
+
+func TestEngineSolvesExamplePuzzle(t *testing.T) {
+	grid := mustParseGrid(t, examplePuzzle)
+	solution := mustParseGrid(t, exampleSolution)
+
+	// The coloring engine alone has to solve the puzzle, without the SAT fallback
+	for run := 0; run < 20; run++ {
+		g, cells, constraints := GridGraph(grid, boxSize(grid))
+		assignment, err := g.ColorConstrained(9, constraints)
+		if err != nil {
+			t.Fatalf("Run %d: engine failed: %v", run, err)
+		}
+		if ok, _, conflicts := g.ColoredWith(assignment, 9); !ok {
+			t.Fatalf("Run %d: solution has conflicts %v", run, conflicts)
+		}
+		for r, row := range cells {
+			for c, node := range row {
+				if assignment[node]+1 != solution[r][c] {
+					t.Fatalf("Run %d: row %d, column %d is %d, expected %d", run, r+1, c+1, assignment[node]+1, solution[r][c])
+				}
+			}
+		}
+	}
+}
+
+func TestSolveGridLatinSquare(t *testing.T) {
+	grid := mustParseGrid(t, "1 . . .\n. . 3 .\n. 4 . .\n. . . 2")
+	solved, _, err := SolveGrid(context.Background(), grid, 0, 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range solved {
+		rows, columns := make(map[int]bool), make(map[int]bool)
+		for j := range solved {
+			rows[solved[i][j]] = true
+			columns[solved[j][i]] = true
+		}
+		if len(rows) != 4 || len(columns) != 4 {
+			t.Fatalf("Row or column %d repeats a symbol:\n%s", i+1, FormatGrid(solved, 0))
+		}
+	}
+}
+
+func TestSolveGridErrors(t *testing.T) {
+	// Two givens repeat a symbol in the first row
+	grid := mustParseGrid(t, strings.Replace(examplePuzzle, "5 3 .", "5 3 5", 1))
+	if _, _, err := SolveGrid(context.Background(), grid, 3, 10*time.Second); err == nil || !strings.Contains(err.Error(), "repeated") {
+		t.Errorf("Expected a repeated symbol error, got %v", err)
+	}
+
+	// No symbol repeats, but the last cell of the first row has no symbol left
+	grid = mustParseGrid(t, "12.\n..3\n...")
+	_, _, err := SolveGrid(context.Background(), grid, 0, 10*time.Second)
+	if err == nil || !strings.Contains(err.Error(), "no solution") || errors.Is(err, ErrTimeout) {
+		t.Errorf("Expected a no solution error, got %v", err)
+	}
+}
+
+func TestSolveGridSATFallback(t *testing.T) {
+	grid := mustParseGrid(t, examplePuzzle)
+	solution := mustParseGrid(t, exampleSolution)
+
+	// The engine has no time at all, so SAT coloring completes the puzzle
+	solved, usedSAT, err := SolveGrid(context.Background(), grid, 3, time.Nanosecond)
+	if err != nil || !usedSAT {
+		t.Fatalf("Expected SAT coloring to solve the puzzle, used %v with error %v", usedSAT, err)
+	}
+	if FormatGrid(solved, 3) != FormatGrid(solution, 3) {
+		t.Fatalf("SAT coloring solved the puzzle as\n%s", FormatGrid(solved, 3))
+	}
+
+	if _, usedSAT, err := SolveGrid(context.Background(), grid, 3, 10*time.Second); err != nil || usedSAT {
+		t.Fatalf("Expected the engine to solve the puzzle, used SAT %v with error %v", usedSAT, err)
+	}
+}
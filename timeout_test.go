@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCancelledContextStopsColoring(t *testing.T) {
+	g, _ := RandomGraph(200, 0, 800, 17)
+	_, k, _ := g.ColorGreedyBest()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, _, err := g.ColorGreedyContext(ctx, LargestFirst, 1); !errors.Is(err, ErrTimeout) {
+		t.Errorf("Greedy coloring returned %v, expected ErrTimeout", err)
+	}
+	if _, err := g.ColorEquitableContext(ctx, k+1, 1); !errors.Is(err, ErrTimeout) {
+		t.Errorf("Equitable coloring returned %v, expected ErrTimeout", err)
+	}
+	if _, err := g.ColorBalancedContext(ctx, k+1, 1); !errors.Is(err, ErrTimeout) {
+		t.Errorf("Balanced coloring returned %v, expected ErrTimeout", err)
+	}
+
+	// Without a deadline the same calls finish
+	if _, _, err := g.ColorGreedyContext(context.Background(), LargestFirst, 1); err != nil {
+		t.Error(err)
+	}
+	if _, err := g.ColorBalancedContext(context.Background(), k+1, 1); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,262 @@
+// Exam timetabling with graph coloring
+
+// Every course is a node, and two courses are adjacent when some student is enrolled in both,
+// since their exams can't be in the same time slot. A coloring of this conflict graph is a timetable
+// with a time slot per color. The best greedy coloring gives the first timetable, and SAT coloring
+// then removes time slots until the number of slots is as small as possible
+
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Courses and the students enrolled in them
+type Enrollment struct {
+	Courses  []string            // Courses in order of appearance
+	Students map[string][]string // Students of each course, sorted
+}
+
+// Exams held in the same time slot
+type TimeSlot struct {
+	Slot     int      `json:"slot"`
+	Courses  []string `json:"courses"`
+	Students int      `json:"students"` // Number of students with an exam in the slot
+}
+
+// Example enrollment for when no input file is given
+const exampleEnrollment = `course,students
+MATH101,alice;bob;carol;dave
+PHYS101,alice;erin;frank
+CHEM101,bob;erin;grace
+CS101,carol;frank;heidi;ivan
+CS102,heidi;judy
+BIO101,dave;grace;judy
+ECON101,ivan;alice
+HIST101,bob;judy;frank
+ART101,erin;ivan
+`
+
+// Read enrollments from CSV, each row has a course followed by its students
+// Students can also be separated by semicolons within a field, the same course can appear
+// on many rows, and a first row starting with "course" is skipped as a header
+func ReadEnrollment(r io.Reader) (*Enrollment, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	enrolled := make(map[string]map[string]bool)
+	e := &Enrollment{Courses: make([]string, 0), Students: make(map[string][]string)}
+
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		course := strings.TrimSpace(record[0])
+		if row == 1 && strings.EqualFold(course, "course") {
+			continue
+		}
+		if len(course) == 0 {
+			return nil, fmt.Errorf("Row %d: missing course", row)
+		}
+
+		if _, ok := enrolled[course]; !ok {
+			enrolled[course] = make(map[string]bool)
+			e.Courses = append(e.Courses, course)
+		}
+		for _, field := range record[1:] {
+			for _, student := range strings.Split(field, ";") {
+				if student = strings.TrimSpace(student); len(student) > 0 {
+					enrolled[course][student] = true
+				}
+			}
+		}
+	}
+
+	if len(e.Courses) == 0 {
+		return nil, errors.New("No courses found")
+	}
+	for course, students := range enrolled {
+		e.Students[course] = sortedNames(students)
+	}
+	return e, nil
+}
+
+// Build the conflict graph, each node holds its course name
+func (e *Enrollment) ConflictGraph() (*Graph[string], map[string]*Node[string]) {
+	g := EmptyGraph[string]()
+	nodes := make(map[string]*Node[string], len(e.Courses))
+	courses := make(map[string][]*Node[string]) // Courses of each student
+	for _, course := range e.Courses {
+		nodes[course] = g.AddNodes(1, course)[0]
+		for _, student := range e.Students[course] {
+			courses[student] = append(courses[student], nodes[course])
+		}
+	}
+
+	// The courses of a student form a clique
+	for _, list := range courses {
+		g.connectClique(list)
+	}
+	return g, nodes
+}
+
+// Schedule the exams into as few time slots as possible
+// On timeout the best timetable found so far is returned with ErrTimeout
+func (e *Enrollment) Schedule(ctx context.Context) ([]TimeSlot, error) {
+	g, nodes := e.ConflictGraph()
+	assignment, slots, _ := g.ColorGreedyBest()
+	assignment, slots, err := g.ColorMinimum(ctx, assignment, slots)
+
+	timetable := make([]TimeSlot, slots)
+	sitting := make([]map[string]bool, slots)
+	for i := range timetable {
+		timetable[i] = TimeSlot{Slot: i + 1, Courses: make([]string, 0)}
+		sitting[i] = make(map[string]bool)
+	}
+	for _, course := range e.Courses {
+		slot := assignment[nodes[course]]
+		timetable[slot].Courses = append(timetable[slot].Courses, course)
+		for _, student := range e.Students[course] {
+			sitting[slot][student] = true
+		}
+	}
+	for i := range timetable {
+		sort.Strings(timetable[i].Courses)
+		timetable[i].Students = len(sitting[i])
+	}
+	return timetable, err
+}
+
+// Write the timetable as CSV with a row for each exam
+func (e *Enrollment) WriteTimetableCSV(w io.Writer, timetable []TimeSlot) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"slot", "course", "students"})
+	for _, slot := range timetable {
+		for _, course := range slot.Courses {
+			writer.Write([]string{strconv.Itoa(slot.Slot), course, strconv.Itoa(len(e.Students[course]))})
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// Save the timetable to path.csv and path.json, any extension of the path is replaced
+func (e *Enrollment) SaveTimetable(timetable []TimeSlot, path string) error {
+	base := strings.TrimSuffix(path, filepath.Ext(path))
+
+	file, err := os.Create(base + ".csv")
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err := e.WriteTimetableCSV(file, timetable); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(timetable, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(base+".json", data, 0644)
+}
+
+// Program to demonstrate exam timetabling with graph coloring
+func RunTimetable() {
+	var In string                  // Input enrollment CSV
+	var Out string                 // Output file without extension
+	var noPrint bool               // Do not print the timetable
+	var noSave bool                // Disable save prompt
+	var timeout = 10 * time.Second // Time budget for minimizing the time slots
+
+	// Extract args
+	for i, v := range os.Args {
+		switch v {
+		case "-I", "--I":
+			fmt.Sscanf(ArgValue(i), "%s", &In)
+		case "-O", "--O":
+			fmt.Sscanf(ArgValue(i), "%s", &Out)
+		case "--timeout":
+			SScanDuration(ArgValue(i), &timeout, "timeout")
+		case "--noprint":
+			noPrint = true
+		case "--nosave":
+			noSave = true
+		}
+	}
+
+	fmt.Println("---- Exam timetabling program ----")
+
+	var source io.Reader = strings.NewReader(exampleEnrollment)
+	if len(In) > 0 {
+		file, err := os.Open(In)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		defer file.Close()
+		source = file
+	} else {
+		fmt.Println("No input file given, using the example enrollment")
+	}
+
+	enrollment, err := ReadEnrollment(source)
+	if err != nil {
+		fmt.Println("Unable to read the enrollment:", err)
+		os.Exit(1)
+	}
+
+	graph, _ := enrollment.ConflictGraph()
+	fmt.Printf("---- %d courses with %d conflicting pairs ----\n", len(graph.nodes), len(graph.edges))
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	timetable, err := enrollment.Schedule(ctx)
+	if err != nil {
+		fmt.Println("Timetable might not have the fewest slots:", err)
+	}
+	fmt.Printf("Time slots needed: %d\n", len(timetable))
+
+	if noPrint {
+		fmt.Println("--noprint specified, skip timetable printing")
+	} else {
+		for _, slot := range timetable {
+			fmt.Printf("Slot %d (%d students): %s\n", slot.Slot, slot.Students, strings.Join(slot.Courses, ", "))
+		}
+	}
+
+	if !noSave && len(Out) < 1 {
+		saveTimetable := "n"
+		fmt.Println("Save timetable to file? (y/n)")
+		fmt.Scanf("%s\n", &saveTimetable)
+		if saveTimetable == "y" {
+			fmt.Print("Filename: ")
+			fmt.Scanf("%s\n", &Out)
+		}
+	}
+
+	if !noSave && len(Out) > 0 {
+		if err := enrollment.SaveTimetable(timetable, Out); err != nil {
+			fmt.Println(err)
+		} else {
+			base := strings.TrimSuffix(Out, filepath.Ext(Out))
+			fmt.Printf("Saved to %s.csv and %s.json\n", base, base)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Random enrollment CSV rows, with the students of each course either in one field
+// separated by semicolons or in separate fields
+func randomEnrollmentRows(r *rand.Rand, semicolons bool) []string {
+	courses := make([][]string, 2+r.Intn(15))
+	for s := 0; s < 5+r.Intn(40); s++ {
+		for k := 0; k < 1+r.Intn(4); k++ {
+			c := r.Intn(len(courses))
+			courses[c] = append(courses[c], fmt.Sprintf("student%d", s))
+		}
+	}
+	rows := make([]string, len(courses))
+	for c, students := range courses {
+		separator := ","
+		if semicolons {
+			separator = ";"
+		}
+		rows[c] = fmt.Sprintf("C%d,%s", c, strings.Join(students, separator))
+	}
+	return rows
+}
+
+// Read an enrollment or fail the test
+func mustReadEnrollment(t *testing.T, rows []string) *Enrollment {
+	t.Helper()
+	e, err := ReadEnrollment(strings.NewReader(strings.Join(rows, "\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func TestReadEnrollmentFormats(t *testing.T) {
+	for seed := int64(1); seed <= 100; seed++ {
+		// The same random rows written both ways
+		rows := randomEnrollmentRows(rand.New(rand.NewSource(seed)), true)
+		commas := randomEnrollmentRows(rand.New(rand.NewSource(seed)), false)
+		e := mustReadEnrollment(t, rows)
+		if other := mustReadEnrollment(t, commas); !reflect.DeepEqual(e, other) {
+			t.Fatalf("Seed %d: semicolons read as %v, commas as %v", seed, e, other)
+		}
+
+		// A header row is skipped, but only as the first row
+		for _, header := range []string{"course,students", "Course, Students"} {
+			if other := mustReadEnrollment(t, append([]string{header}, rows...)); !reflect.DeepEqual(e, other) {
+				t.Fatalf("Seed %d: header %q read as %v", seed, header, other)
+			}
+		}
+		if other := mustReadEnrollment(t, append(rows, "course,x")); reflect.DeepEqual(e, other) {
+			t.Fatalf("Seed %d: a course named course after the first row was skipped", seed)
+		}
+	}
+}
+
+func TestReadEnrollmentErrors(t *testing.T) {
+	for _, input := range []string{"", "course,students", ",alice", "A,\"unterminated"} {
+		if _, err := ReadEnrollment(strings.NewReader(input)); err == nil {
+			t.Errorf("Expected an error for %q", input)
+		}
+	}
+}
+
+func TestScheduleSeparatesSharedStudents(t *testing.T) {
+	for seed := int64(1); seed <= 100; seed++ {
+		e := mustReadEnrollment(t, randomEnrollmentRows(rand.New(rand.NewSource(seed)), true))
+		timetable, err := e.Schedule(context.Background())
+		if err != nil {
+			t.Fatalf("Seed %d: %v", seed, err)
+		}
+
+		slotOf := make(map[string]int)
+		for _, slot := range timetable {
+			for _, course := range slot.Courses {
+				if _, ok := slotOf[course]; ok {
+					t.Fatalf("Seed %d: course %s is in two slots", seed, course)
+				}
+				slotOf[course] = slot.Slot
+			}
+		}
+		if len(slotOf) != len(e.Courses) {
+			t.Fatalf("Seed %d: %d of %d courses scheduled", seed, len(slotOf), len(e.Courses))
+		}
+
+		// No student has two exams in the same slot
+		for _, slot := range timetable {
+			seen := make(map[string]string)
+			for _, course := range slot.Courses {
+				for _, student := range e.Students[course] {
+					if other, ok := seen[student]; ok {
+						t.Fatalf("Seed %d: %s has %s and %s in slot %d", seed, student, other, course, slot.Slot)
+					}
+					seen[student] = course
+				}
+			}
+			if slot.Students != len(seen) {
+				t.Fatalf("Seed %d: slot %d reports %d students, %d sit in it", seed, slot.Slot, slot.Students, len(seen))
+			}
+		}
+	}
+}
+
+func TestSaveTimetableRoundTrip(t *testing.T) {
+	e := mustReadEnrollment(t, strings.Split(strings.TrimSpace(exampleEnrollment), "\n"))
+	timetable, err := e.Schedule(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "timetable.txt")
+	if err := e.SaveTimetable(timetable, path); err != nil {
+		t.Fatal(err)
+	}
+	base := strings.TrimSuffix(path, ".txt")
+
+	// The JSON file holds the timetable as it is
+	data, err := os.ReadFile(base + ".json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved []TimeSlot
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(saved, timetable) {
+		t.Fatalf("Saved timetable %v, expected %v", saved, timetable)
+	}
+
+	// The CSV file has a row for every exam with its slot and number of students
+	file, err := os.Open(base + ".csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rebuilt := make([]TimeSlot, len(timetable))
+	for i := range rebuilt {
+		rebuilt[i] = TimeSlot{Slot: i + 1, Courses: make([]string, 0), Students: timetable[i].Students}
+	}
+	for _, record := range records[1:] {
+		slot, err := strconv.Atoi(record[0])
+		if err != nil || slot < 1 || slot > len(rebuilt) {
+			t.Fatalf("Row %v has an invalid slot", record)
+		}
+		if students, _ := strconv.Atoi(record[2]); students != len(e.Students[record[1]]) {
+			t.Fatalf("Row %v has %d students, expected %d", record, students, len(e.Students[record[1]]))
+		}
+		rebuilt[slot-1].Courses = append(rebuilt[slot-1].Courses, record[1])
+	}
+	if !reflect.DeepEqual(rebuilt, timetable) {
+		t.Fatalf("CSV describes timetable %v, expected %v", rebuilt, timetable)
+	}
+}
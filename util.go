@@ -2,58 +2,106 @@ package main
 
 import (
 	"fmt"
+	"math"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
-// Read int from a string and exit on fail
-func SScanInt(s string, i *int, name string) {
-	if scanned, err := fmt.Sscanf(s, "%d", i); err != nil || scanned < 1 {
-		fmt.Println("Failed to read param")
-		os.Exit(1)
+// Parse a positive integer
+func ParseInt(s string, name string) (int, error) {
+	i, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("Failed to read %s from %q", name, s)
 	}
-
-	if *i <= 0 {
-		fmt.Printf("%s must be a positive integer\n", name)
-		os.Exit(1)
+	if i <= 0 {
+		return 0, fmt.Errorf("%s must be a positive integer", name)
 	}
+	return i, nil
 }
 
-// Read int from the user and exit on fail
-func ScanInt(i *int, name string) {
-	if scanned, err := fmt.Scanf("%d\n", i); err != nil || scanned < 1 {
-		fmt.Println("Failed to read param")
-		os.Exit(1)
+// Parse a positive finite number
+func ParseFloat(s string, name string) (float64, error) {
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0, fmt.Errorf("Failed to read %s from %q", name, s)
 	}
+	if !(f > 0) || math.IsInf(f, 1) {
+		return 0, fmt.Errorf("%s must be a positive number", name)
+	}
+	return f, nil
+}
 
-	if *i <= 0 {
-		fmt.Printf("%s must be a positive integer\n", name)
-		os.Exit(1)
+// Parse a positive duration such as 10s or 1m30s
+func ParseDuration(s string, name string) (time.Duration, error) {
+	d, err := time.ParseDuration(strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("Failed to read %s from %q", name, s)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("%s must be a positive duration", name)
 	}
+	return d, nil
 }
 
-// Read int from a string and exit on fail
-func SScanFloat(s string, f *float64, name string) {
-	if scanned, err := fmt.Sscanf(s, "%f", f); err != nil || scanned < 1 {
-		fmt.Println("Failed to read param")
-		os.Exit(1)
+// Value following the flag at index i of args
+func FlagValue(args []string, i int) (string, error) {
+	if i < 0 || i >= len(args) {
+		return "", fmt.Errorf("No argument at index %d", i)
 	}
+	if i+1 >= len(args) {
+		return "", fmt.Errorf("Missing value for %s", args[i])
+	}
+	return args[i+1], nil
+}
+
+// Value following the flag at index i of the command line arguments, exit if it is missing
+func ArgValue(i int) string {
+	v, err := FlagValue(os.Args, i)
+	exitOnError(err)
+	return v
+}
 
-	if *f <= 0 {
-		fmt.Printf("%s must be a positive number\n", name)
+// Print the error and exit if there is one
+func exitOnError(err error) {
+	if err != nil {
+		fmt.Println(err)
 		os.Exit(1)
 	}
 }
 
+// Read int from a string and exit on fail
+func SScanInt(s string, i *int, name string) {
+	var err error
+	*i, err = ParseInt(s, name)
+	exitOnError(err)
+}
+
+// Read int from the user and exit on fail
+func ScanInt(i *int, name string) {
+	var s string
+	fmt.Scanf("%s\n", &s)
+	SScanInt(s, i, name)
+}
+
+// Read float from a string and exit on fail
+func SScanFloat(s string, f *float64, name string) {
+	var err error
+	*f, err = ParseFloat(s, name)
+	exitOnError(err)
+}
+
 // Read float from the user and exit on fail
 func ScanFloat(f *float64, name string) {
-	if scanned, err := fmt.Scanf("%f\n", f); err != nil || scanned < 1 {
-		fmt.Println(err)
-		fmt.Println("Failed to read param")
-		os.Exit(1)
-	}
+	var s string
+	fmt.Scanf("%s\n", &s)
+	SScanFloat(s, f, name)
+}
 
-	if *f <= 0 {
-		fmt.Printf("%s must be a positive number\n", name)
-		os.Exit(1)
-	}
+// Read a duration such as 10s or 1m30s from a string and exit on fail
+func SScanDuration(s string, d *time.Duration, name string) {
+	var err error
+	*d, err = ParseDuration(s, name)
+	exitOnError(err)
 }